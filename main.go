@@ -1,8 +1,20 @@
 package main
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"hash/crc32"
+	"io"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 )
 
@@ -12,179 +24,2858 @@ const (
 	MinInHours = 60   // количество минут в одном часе
 	LenStep    = 0.65 // длина одного шага
 	CmInM      = 100  // количество сантиметров в одном метре
+	SecInHour  = 3600 // количество секунд в одном часе
+)
+
+// Названия типов тренировок.
+const (
+	RunningType  = "Бег"
+	WalkingType  = "Ходьба"
+	SwimmingType = "Плавание"
 )
 
 // Training общая структура для всех тренировок
 type Training struct {
-	...        	// тип тренировки
-	...         // количество повторов(шаги, гребки при плавании)
-	...       	// длина одного шага или гребка в м
-	... 		// продолжительность тренировки
-	...       	// вес пользователя в кг
+	TrainingType  string          // тип тренировки
+	Action        int             // количество повторов(шаги, гребки при плавании)
+	LenStep       float64         // длина одного шага или гребка в м
+	Duration      time.Duration   // продолжительность тренировки
+	Weight        float64         // вес пользователя в кг
+	Date          time.Time       // дата и время начала тренировки
+	AvgHeartRate  int             // средний пульс за тренировку, уд/мин
+	FatigueModel  bool            // учитывать снижение расхода килокалорий из-за утомления
+	HRZoneMinutes map[int]float64 // минуты, проведенные в каждой пульсовой зоне
+	Pauses        []time.Duration // паузы во время тренировки
+	SpeedPoints   []float64       // скорость в км/ч в точках трека, записанных через равные промежутки времени
+	DaysOff       int             // количество дней без тренировок перед этой тренировкой
 }
 
 // distance возвращает дистанцию, которую преодолел пользователь.
 // Формула расчета:
 // количество_повторов * длина_шага / м_в_км
 func (t Training) distance() float64 {
-	// вставьте ваш код ниже
-	...
+	return DistanceFromSteps(t.Action, t.LenStep)
+}
+
+// DistanceFromSteps возвращает дистанцию в км по количеству шагов и длине шага в м.
+// Для отрицательного количества шагов возвращает 0.
+// Формула расчета:
+// количество_шагов * длина_шага / м_в_км
+func DistanceFromSteps(steps int, stepLen float64) float64 {
+	if steps < 0 {
+		return 0
+	}
+	return float64(steps) * stepLen / MInKm
+}
+
+// CalibrateStepLength возвращает длину шага в м по дистанции в км, измеренной GPS, и количеству шагов.
+// Результат можно использовать как LenStep тренировки. При неположительных значениях возвращает 0.
+// Формула расчета:
+// дистанция_в_км * м_в_км / количество_шагов
+func CalibrateStepLength(distanceKm float64, steps int) float64 {
+	if distanceKm <= 0 || steps <= 0 {
+		return 0
+	}
+	return distanceKm * MInKm / float64(steps)
+}
+
+// movingTime возвращает время движения: продолжительность тренировки без пауз.
+// По времени движения рассчитываются скорость, темп, каденс, калории и все показатели,
+// основанные на расходе или нагрузке в единицу времени. В информации о тренировке выводится
+// полная продолжительность, и по ней намеренно считаются показатели, зависящие от времени на часах:
+// отрыв от пейсера, потеря жидкости, расход в покое за то же время и кривая расхода по ходу тренировки.
+// Неположительные паузы не учитываются, поэтому время движения не превышает продолжительность.
+func (t Training) movingTime() time.Duration {
+	moving := t.Duration
+	for _, pause := range t.Pauses {
+		if pause > 0 {
+			moving -= pause
+		}
+	}
+	if moving < 0 {
+		return 0
+	}
+	return moving
 }
 
 // meanSpeed возвращает среднюю скорость бега или ходьбы.
 func (t Training) meanSpeed() float64 {
-	// вставьте ваш код ниже
-	...	
+	moving := t.movingTime()
+	if moving <= 0 {
+		return 0
+	}
+	return t.distance() / moving.Hours()
 }
 
 // Calories возвращает количество потраченных килокалорий на тренировке.
 // Пока возвращаем 0, так как этот метод будет переопределяться для каждого типа тренировки.
 func (t Training) Calories() float64 {
-	// вставьте ваш код ниже
-	...
+	return 0
+}
+
+// Коэффициенты формулы TRIMP (Банистер).
+const (
+	TRIMPMaleMultiplier   = 0.64 // множитель для мужчин
+	TRIMPMaleExponent     = 1.92 // показатель экспоненты для мужчин
+	TRIMPFemaleMultiplier = 0.86 // множитель для женщин
+	TRIMPFemaleExponent   = 1.67 // показатель экспоненты для женщин
+)
+
+// heartRateReserve возвращает долю резерва пульса, использованную на тренировке.
+// Если пульс не задан или параметры некорректны, возвращает 0.
+// Формула расчета:
+// (средний_пульс - пульс_покоя) / (максимальный_пульс - пульс_покоя)
+func (t Training) heartRateReserve(maxHR, restHR int) float64 {
+	if t.AvgHeartRate <= 0 || maxHR <= restHR {
+		return 0
+	}
+	return float64(t.AvgHeartRate-restHR) / float64(maxHR-restHR)
+}
+
+// TRIMP возвращает тренировочную нагрузку по Банистеру.
+// Если средний пульс не задан, возвращает 0.
+// Формула расчета:
+// время_движения_в_минутах * доля_резерва_пульса * k * e^(b * доля_резерва_пульса),
+// где k = 0.64, b = 1.92 для мужчин и k = 0.86, b = 1.67 для женщин.
+func (t Training) TRIMP(maxHR, restHR int, isMale bool) float64 {
+	reserve := t.heartRateReserve(maxHR, restHR)
+	if reserve <= 0 {
+		return 0
+	}
+	return t.movingTime().Minutes() * trimpPerMinute(reserve, isMale)
+}
+
+// trimpPerMinute возвращает нагрузку TRIMP за минуту при доле резерва пульса reserve.
+func trimpPerMinute(reserve float64, isMale bool) float64 {
+	multiplier, exponent := TRIMPFemaleMultiplier, TRIMPFemaleExponent
+	if isMale {
+		multiplier, exponent = TRIMPMaleMultiplier, TRIMPMaleExponent
+	}
+	return reserve * multiplier * math.Exp(exponent*reserve)
+}
+
+// relativeEffortZoneReserve содержит для каждой пульсовой зоны из HRZoneMinutes
+// долю резерва пульса в середине зоны: зоны 1–5 соответствуют 50–60, 60–70, 70–80, 80–90 и 90–100%.
+var relativeEffortZoneReserve = map[int]float64{
+	1: 0.55,
+	2: 0.65,
+	3: 0.75,
+	4: 0.85,
+	5: 0.95,
+}
+
+// RelativeEffort возвращает целочисленную оценку относительной нагрузки в стиле Strava:
+// время тренировки, взвешенное по пульсу.
+// Если записано время по зонам, минуты каждой зоны умножаются на вес зоны — нагрузку TRIMP за минуту
+// при доле резерва пульса в середине зоны (relativeEffortZoneReserve). Для мужчин веса зон 1–5
+// примерно 1.0, 1.4, 2.0, 2.8 и 3.8, для женщин — 1.2, 1.7, 2.3, 3.0 и 4.0; зоны вне 1–5 не учитываются.
+// Иначе оценка считается по доле резерва среднего пульса за все время движения, то есть равна TRIMP.
+// Если не заданы ни зоны, ни средний пульс, возвращает 0.
+// Формула расчета по зонам:
+// сумма(минуты_в_зоне * вес_зоны)
+func (t Training) RelativeEffort(maxHR, restHR int, isMale bool) int {
+	if len(t.HRZoneMinutes) == 0 {
+		return int(math.Round(t.TRIMP(maxHR, restHR, isMale)))
+	}
+	var effort float64
+	for zone, minutes := range t.HRZoneMinutes {
+		if reserve, ok := relativeEffortZoneReserve[zone]; ok && minutes > 0 {
+			effort += minutes * trimpPerMinute(reserve, isMale)
+		}
+	}
+	return int(math.Round(effort))
+}
+
+// Константы модели утомления.
+const (
+	FatigueThreshold   = 90 * time.Minute // длительность, после которой начинает сказываться утомление
+	FatigueDecayFactor = 0.9              // доля расхода килокалорий после порога утомления
+)
+
+// Константы модели возвращения после перерыва.
+const (
+	ComebackGraceDays    = 7   // перерыв, не влияющий на форму, в днях
+	ComebackMaxDiscount  = 0.2 // максимальное снижение расхода килокалорий после длительного перерыва
+	ComebackTimeConstant = 30  // характерное время потери формы в днях
+)
+
+// ComebackFactor возвращает множитель расхода килокалорий после перерыва в daysOff дней.
+// Перерыв до ComebackGraceDays не влияет на результат, дальше потеря формы
+// экспоненциально приближается к ComebackMaxDiscount.
+// Формула расчета:
+// 1 - ComebackMaxDiscount * (1 - e^(-(дни_перерыва - ComebackGraceDays) / ComebackTimeConstant))
+func ComebackFactor(daysOff int) float64 {
+	if daysOff <= ComebackGraceDays {
+		return 1
+	}
+	return 1 - ComebackMaxDiscount*(1-math.Exp(-float64(daysOff-ComebackGraceDays)/ComebackTimeConstant))
+}
+
+// minCaloriesPerMinute минимальный расход килокалорий в минуту, применяемый ко всем тренировкам.
+var minCaloriesPerMinute float64
+
+// SetMinCaloriesPerMinute задает минимальный расход килокалорий в минуту для всех тренировок.
+// Значение 0, используемое по умолчанию, отключает ограничение.
+func SetMinCaloriesPerMinute(v float64) {
+	minCaloriesPerMinute = math.Max(0, v)
+}
+
+// adjustCalories возвращает количество килокалорий с учетом включенных поправок тренировки.
+// При включенной модели утомления расход килокалорий после FatigueThreshold
+// составляет FatigueDecayFactor от обычного.
+// Если указан перерыв перед тренировкой, расход уменьшается на ComebackFactor.
+// Результат не меньше минимального расхода, заданного SetMinCaloriesPerMinute.
+// Формула расчета:
+// ккал * (порог + (время_движения - порог) * FatigueDecayFactor) / время_движения
+func (t Training) adjustCalories(calories float64) float64 {
+	moving := t.movingTime()
+	if t.FatigueModel && moving > FatigueThreshold {
+		tail := float64(moving - FatigueThreshold)
+		calories *= (float64(FatigueThreshold) + tail*FatigueDecayFactor) / float64(moving)
+	}
+	calories *= ComebackFactor(t.DaysOff)
+	return math.Max(calories, minCaloriesPerMinute*moving.Minutes())
 }
 
 // InfoMessage содержит информацию о проведенной тренировке.
 type InfoMessage struct {
-	// добавьте необходимые поля в структуру
-	...     // тип тренировки
-	... 	// длительность тренировки
-	...     // расстояние, которое преодолел пользователь
-	...     // средняя скорость, с которой двигался пользователь
-	...     // количество потраченных килокалорий на тренировке
+	TrainingType string        // тип тренировки
+	Duration     time.Duration // длительность тренировки
+	Distance     float64       // расстояние, которое преодолел пользователь
+	Speed        float64       // средняя скорость, с которой двигался пользователь
+	Calories     float64       // количество потраченных килокалорий на тренировке
+	Note         string        // заметка пользователя о тренировке
 }
 
 // TrainingInfo возвращает труктуру InfoMessage, в которой хранится вся информация о проведенной тренировке.
 func (t Training) TrainingInfo() InfoMessage {
-	// вставьте ваш код ниже
-	...
+	return InfoMessage{
+		TrainingType: t.TrainingType,
+		Duration:     t.Duration,
+		Distance:     t.distance(),
+		Speed:        t.meanSpeed(),
+		Calories:     t.Calories(),
+	}
+}
+
+// formatFloat возвращает число с двумя знаками после запятой.
+// Отрицательный ноль, например после округления -1e-15, выводится как "0.00".
+func formatFloat(v float64) string {
+	return formatFixed(v, 2)
+}
+
+// formatRound возвращает число, округленное до целого.
+// Отрицательный ноль выводится как "0".
+func formatRound(v float64) string {
+	return formatFixed(v, 0)
+}
+
+// formatFixed возвращает число с prec знаками после запятой без знака минус у нуля.
+func formatFixed(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if strings.Trim(s, "-0.") == "" {
+		return strings.TrimPrefix(s, "-")
+	}
+	return s
 }
 
 // String возвращает строку с информацией о проведенной тренировке.
 func (i InfoMessage) String() string {
-	return fmt.Sprintf("Тип тренировки: %s\nДлительность: %v мин\nДистанция: %.2f км.\nСр. скорость: %.2f км/ч\nПотрачено ккал: %.2f\n",
+	s := fmt.Sprintf("Тип тренировки: %s\nДлительность: %v мин\nДистанция: %s км.\nСр. скорость: %s км/ч\nПотрачено ккал: %s\n",
 		i.TrainingType,
 		i.Duration.Minutes(),
-		i.Distance,
-		i.Speed,
-		i.Calories,
+		formatFloat(i.Distance),
+		formatFloat(i.Speed),
+		formatFloat(i.Calories),
+	)
+	if i.Note != "" {
+		s += fmt.Sprintf("Заметка: %s\n", i.Note)
+	}
+	return s
+}
+
+// FormatWith возвращает информацию о проведенной тренировке, оформленную по пользовательскому шаблону.
+// В шаблоне доступны поля InfoMessage, например {{.Distance}}.
+func (i InfoMessage) FormatWith(tmpl *template.Template) (string, error) {
+	if tmpl == nil {
+		return "", errors.New("шаблон не задан")
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, i); err != nil {
+		return "", fmt.Errorf("ошибка применения шаблона: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// MarshalBinary возвращает компактное двоичное представление InfoMessage.
+// Формат (порядок байт big-endian):
+// длина_типа (uint16), тип (UTF-8), длительность в нс (int64),
+// дистанция, скорость, калории (float64), длина_заметки (uint16), заметка (UTF-8).
+func (i InfoMessage) MarshalBinary() ([]byte, error) {
+	if len(i.TrainingType) > math.MaxUint16 || len(i.Note) > math.MaxUint16 {
+		return nil, errors.New("слишком длинная строка для двоичного формата")
+	}
+	data := make([]byte, 0, 2+len(i.TrainingType)+8*4+2+len(i.Note))
+	data = binary.BigEndian.AppendUint16(data, uint16(len(i.TrainingType)))
+	data = append(data, i.TrainingType...)
+	data = binary.BigEndian.AppendUint64(data, uint64(i.Duration))
+	data = binary.BigEndian.AppendUint64(data, math.Float64bits(i.Distance))
+	data = binary.BigEndian.AppendUint64(data, math.Float64bits(i.Speed))
+	data = binary.BigEndian.AppendUint64(data, math.Float64bits(i.Calories))
+	data = binary.BigEndian.AppendUint16(data, uint16(len(i.Note)))
+	data = append(data, i.Note...)
+	return data, nil
+}
+
+// UnmarshalBinary восстанавливает InfoMessage из двоичного представления, полученного MarshalBinary.
+func (i *InfoMessage) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	readString := func() (string, error) {
+		var n uint16
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return "", err
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+
+	var msg InfoMessage
+	var err error
+	if msg.TrainingType, err = readString(); err != nil {
+		return fmt.Errorf("ошибка чтения типа тренировки: %w", err)
+	}
+	var fields struct {
+		Duration int64
+		Distance float64
+		Speed    float64
+		Calories float64
+	}
+	if err := binary.Read(r, binary.BigEndian, &fields); err != nil {
+		return fmt.Errorf("ошибка чтения показателей тренировки: %w", err)
+	}
+	msg.Duration = time.Duration(fields.Duration)
+	msg.Distance, msg.Speed, msg.Calories = fields.Distance, fields.Speed, fields.Calories
+	if msg.Note, err = readString(); err != nil {
+		return fmt.Errorf("ошибка чтения заметки: %w", err)
+	}
+	if r.Len() != 0 {
+		return errors.New("лишние данные в двоичном представлении")
+	}
+	*i = msg
+	return nil
+}
+
+// DefaultMetricsPrefix префикс метрик Prometheus по умолчанию.
+const DefaultMetricsPrefix = "workout"
+
+// escapeLabelValue экранирует значение метки Prometheus.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// PrometheusLines возвращает показатели тренировки в текстовом формате метрик Prometheus,
+// например: workout_distance_km{type="Бег"} 5.00.
+// Если prefix пустой, используется DefaultMetricsPrefix.
+func (i InfoMessage) PrometheusLines(prefix string) []string {
+	if prefix == "" {
+		prefix = DefaultMetricsPrefix
+	}
+	labels := fmt.Sprintf(`{type="%s"}`, escapeLabelValue(i.TrainingType))
+	return []string{
+		fmt.Sprintf("%s_duration_minutes%s %s", prefix, labels, formatFloat(i.Duration.Minutes())),
+		fmt.Sprintf("%s_distance_km%s %s", prefix, labels, formatFloat(i.Distance)),
+		fmt.Sprintf("%s_speed_kmh%s %s", prefix, labels, formatFloat(i.Speed)),
+		fmt.Sprintf("%s_calories_kcal%s %s", prefix, labels, formatFloat(i.Calories)),
+	}
+}
+
+// trainingEmoji содержит эмодзи для каждого типа тренировки.
+var trainingEmoji = map[string]string{
+	RunningType:  "🏃",
+	WalkingType:  "🚶",
+	SwimmingType: "🏊",
+}
+
+// DefaultTrainingEmoji эмодзи для неизвестного типа тренировки.
+const DefaultTrainingEmoji = "💪"
+
+// ShareText возвращает краткую строку о тренировке для публикации в соцсетях,
+// например: "🏃 5.00 км за 30 мин, 250 ккал!".
+func (i InfoMessage) ShareText() string {
+	emoji, ok := trainingEmoji[i.TrainingType]
+	if !ok {
+		emoji = DefaultTrainingEmoji
+	}
+	return fmt.Sprintf("%s %s км за %s мин, %s ккал!",
+		emoji, formatFloat(i.Distance), formatRound(i.Duration.Minutes()), formatRound(i.Calories))
+}
+
+// foodCalories содержит калорийность распространенных продуктов в ккал на порцию.
+var foodCalories = map[string]float64{
+	"шоколадный батончик": 230,
+	"банан":               105,
+	"пончик":              250,
+	"кусок пиццы":         285,
+	"стакан колы":         140,
+}
+
+// FoodEquivalents возвращает, сколько порций каждого продукта из таблицы foodCalories
+// соответствует потраченным на тренировке килокалориям.
+func (i InfoMessage) FoodEquivalents() map[string]float64 {
+	equivalents := make(map[string]float64, len(foodCalories))
+	for food, calories := range foodCalories {
+		equivalents[food] = i.Calories / calories
+	}
+	return equivalents
+}
+
+// AltText возвращает краткое описание тренировки для альтернативного текста изображения карточки.
+func (i InfoMessage) AltText() string {
+	return fmt.Sprintf("Карточка тренировки: %s, дистанция %s км за %s мин, потрачено %s ккал.",
+		i.TrainingType,
+		formatFloat(i.Distance),
+		formatRound(i.Duration.Minutes()),
+		formatRound(i.Calories),
+	)
+}
+
+// Слова для записи чисел прописью.
+var (
+	numberOnes     = [...]string{"", "один", "два", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	numberOnesFem  = [...]string{"", "одну", "две"}
+	numberTeens    = [...]string{"десять", "одиннадцать", "двенадцать", "тринадцать", "четырнадцать", "пятнадцать", "шестнадцать", "семнадцать", "восемнадцать", "девятнадцать"}
+	numberTens     = [...]string{"", "", "двадцать", "тридцать", "сорок", "пятьдесят", "шестьдесят", "семьдесят", "восемьдесят", "девяносто"}
+	numberHundreds = [...]string{"", "сто", "двести", "триста", "четыреста", "пятьсот", "шестьсот", "семьсот", "восемьсот", "девятьсот"}
+)
+
+// plural возвращает форму слова, согласованную с числом n: one для 1, few для 2–4, many для остальных.
+func plural(n int, one, few, many string) string {
+	n %= 100
+	if n >= 11 && n <= 19 {
+		return many
+	}
+	switch n % 10 {
+	case 1:
+		return one
+	case 2, 3, 4:
+		return few
+	}
+	return many
+}
+
+// spellHundreds возвращает прописью число от 0 до 999 в винительном падеже.
+// Для слов женского рода используются формы «одну» и «две».
+func spellHundreds(n int, feminine bool) []string {
+	var words []string
+	if n >= 100 {
+		words = append(words, numberHundreds[n/100])
+	}
+	n %= 100
+	switch {
+	case n >= 10 && n <= 19:
+		return append(words, numberTeens[n-10])
+	case n >= 20:
+		words = append(words, numberTens[n/10])
+		n %= 10
+	}
+	switch {
+	case n == 0:
+	case feminine && n <= 2:
+		words = append(words, numberOnesFem[n])
+	default:
+		words = append(words, numberOnes[n])
+	}
+	return words
+}
+
+// spellNumber возвращает прописью неотрицательное число меньше миллиона в винительном падеже.
+// Большие и отрицательные числа возвращаются цифрами.
+func spellNumber(n int, feminine bool) string {
+	switch {
+	case n == 0:
+		return "ноль"
+	case n < 0 || n >= 1000000:
+		return strconv.Itoa(n)
+	}
+	var words []string
+	switch thousands := n / 1000; {
+	case thousands == 1:
+		// «тысячу», а не «одну тысячу»
+		words = []string{"тысячу"}
+	case thousands > 1:
+		words = append(spellHundreds(thousands, true), plural(thousands, "тысячу", "тысячи", "тысяч"))
+	}
+	words = append(words, spellHundreds(n%1000, feminine)...)
+	return strings.Join(words, " ")
+}
+
+// voiceVerbs содержит глаголы для голосовой сводки по типам тренировок.
+var voiceVerbs = map[string]string{
+	RunningType:  "пробежали",
+	WalkingType:  "прошли",
+	SwimmingType: "проплыли",
+}
+
+// Точность дистанции в голосовой сводке.
+const (
+	VoiceWholeKmDistance = 10 // дистанция в км, начиная с которой она округляется до целых километров
+	VoiceMetersStep      = 10 // шаг округления метров в дистанции от километра до VoiceWholeKmDistance
+)
+
+// spellMeters возвращает количество метров прописью.
+func spellMeters(m int) string {
+	return spellNumber(m, false) + " " + plural(m, "метр", "метра", "метров")
+}
+
+// spellKilometers возвращает количество километров прописью.
+func spellKilometers(km int) string {
+	return spellNumber(km, false) + " " + plural(km, "километр", "километра", "километров")
+}
+
+// VoiceSummary возвращает сводку о тренировке для голосового помощника:
+// без символов и с числами прописью, например
+// «Вы пробежали пять километров за тридцать минут и потратили двести пятьдесят килокалорий».
+// Дистанция меньше километра называется в метрах, до VoiceWholeKmDistance км — в километрах и метрах
+// с точностью до VoiceMetersStep м, а длиннее — в целых километрах.
+func (i InfoMessage) VoiceSummary() string {
+	verb, ok := voiceVerbs[i.TrainingType]
+	if !ok {
+		verb = "преодолели"
+	}
+	var distance string
+	switch m := int(math.Round(i.Distance * MInKm)); {
+	case m < MInKm:
+		distance = spellMeters(m)
+	case i.Distance < VoiceWholeKmDistance:
+		m = int(math.Round(i.Distance*MInKm/VoiceMetersStep)) * VoiceMetersStep
+		distance = spellKilometers(m / MInKm)
+		if rest := m % MInKm; rest > 0 {
+			distance += " " + spellMeters(rest)
+		}
+	default:
+		distance = spellKilometers(int(math.Round(i.Distance)))
+	}
+	minutes := int(math.Round(i.Duration.Minutes()))
+	calories := int(math.Round(i.Calories))
+	return fmt.Sprintf("Вы %s %s за %s %s и потратили %s %s",
+		verb,
+		distance,
+		spellNumber(minutes, true), plural(minutes, "минуту", "минуты", "минут"),
+		spellNumber(calories, true), plural(calories, "килокалорию", "килокалории", "килокалорий"),
 	)
 }
 
 // CaloriesCalculator интерфейс для структур: Running, Walking и Swimming.
 type CaloriesCalculator interface {
-	// добавьте необходимые методы в интерфейс
-	...
+	Calories() float64
+	TrainingInfo() InfoMessage
 }
 
-// Константы для расчета потраченных килокалорий при беге.
+// trainingData необязательный интерфейс для доступа к общим данным тренировки.
+// Его реализуют все типы, встраивающие Training.
+type trainingData interface {
+	data() Training
+}
+
+// data возвращает общие данные тренировки.
+func (t Training) data() Training {
+	return t
+}
+
+// dataOf возвращает общие данные тренировки или пустую структуру Training,
+// если тренировка не встраивает Training.
+func dataOf(training CaloriesCalculator) Training {
+	if d, ok := training.(trainingData); ok {
+		return d.data()
+	}
+	return Training{}
+}
+
+// movingTimeOf возвращает время движения тренировки.
+// Для тренировки, не встраивающей Training, возвращает продолжительность из TrainingInfo.
+func movingTimeOf(training CaloriesCalculator) time.Duration {
+	if d, ok := training.(trainingData); ok {
+		return d.data().movingTime()
+	}
+	return training.TrainingInfo().Duration
+}
+
+// kindParams содержит параметры типа тренировки для производных показателей.
+type kindParams struct {
+	moderateSpeed float64 // скорость в км/ч, с которой тренировка считается умеренной
+	vigorousSpeed float64 // скорость в км/ч, с которой тренировка считается интенсивной
+	proxyMinSpeed float64 // скорость в км/ч, соответствующая интенсивности 0
+	proxyMaxSpeed float64 // скорость в км/ч, соответствующая интенсивности 1
+	activity      string  // тип активности во внешних фитнес-сервисах
+	fitSport      string  // вид спорта в формате FIT
+}
+
+// paramsOf возвращает параметры типа тренировки.
+// Для неизвестного типа возвращает нулевые параметры и false.
+func paramsOf(training CaloriesCalculator) (kindParams, bool) {
+	switch training.(type) {
+	case Running:
+		return kindParams{RunningModerateSpeed, RunningVigorousSpeed, RunningProxyMinSpeed, RunningProxyMaxSpeed, ActivityRun, "running"}, true
+	case Walking:
+		return kindParams{WalkingModerateSpeed, WalkingVigorousSpeed, WalkingProxyMinSpeed, WalkingProxyMaxSpeed, ActivityWalk, "walking"}, true
+	case Swimming:
+		return kindParams{SwimmingModerateSpeed, SwimmingVigorousSpeed, SwimmingProxyMinSpeed, SwimmingProxyMaxSpeed, ActivitySwim, "swimming"}, true
+	}
+	return kindParams{}, false
+}
+
+// TimeToBurn возвращает время, которое нужно продолжать тренировку в текущем темпе,
+// чтобы потратить targetCalories килокалорий.
+// Если цель уже достигнута или темп расхода калорий нулевой, возвращает 0.
+func TimeToBurn(training CaloriesCalculator, targetCalories float64) time.Duration {
+	rate := burnRate(training) // ккал в минуту
+	remaining := targetCalories - training.Calories()
+	if rate <= 0 || remaining <= 0 {
+		return 0
+	}
+	return time.Duration(remaining / rate * float64(time.Minute))
+}
+
+// InfoDiff содержит разницу показателей тренировки относительно базового значения.
+type InfoDiff struct {
+	Duration        time.Duration // разница длительности тренировки
+	Distance        float64       // разница расстояния в км
+	Speed           float64       // разница средней скорости в км/ч
+	Calories        float64       // разница потраченных килокалорий
+	DurationPercent float64       // изменение длительности в процентах
+	DistancePercent float64       // изменение расстояния в процентах
+	SpeedPercent    float64       // изменение средней скорости в процентах
+	CaloriesPercent float64       // изменение потраченных килокалорий в процентах
+}
+
+// percentChange возвращает изменение значения current относительно base в процентах.
+// При нулевом базовом значении возвращает 0.
+func percentChange(current, base float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return (current - base) / base * 100
+}
+
+// diffInfo возвращает разницу показателей current относительно base.
+func diffInfo(current, base InfoMessage) InfoDiff {
+	return InfoDiff{
+		Duration:        current.Duration - base.Duration,
+		Distance:        current.Distance - base.Distance,
+		Speed:           current.Speed - base.Speed,
+		Calories:        current.Calories - base.Calories,
+		DurationPercent: percentChange(float64(current.Duration), float64(base.Duration)),
+		DistancePercent: percentChange(current.Distance, base.Distance),
+		SpeedPercent:    percentChange(current.Speed, base.Speed),
+		CaloriesPercent: percentChange(current.Calories, base.Calories),
+	}
+}
+
+// averageInfo возвращает средние показатели по списку тренировок.
+func averageInfo(trainings []CaloriesCalculator) InfoMessage {
+	var avg InfoMessage
+	if len(trainings) == 0 {
+		return avg
+	}
+	for _, training := range trainings {
+		info := training.TrainingInfo()
+		avg.Duration += info.Duration
+		avg.Distance += info.Distance
+		avg.Speed += info.Speed
+		avg.Calories += info.Calories
+	}
+	n := float64(len(trainings))
+	avg.Duration = time.Duration(float64(avg.Duration) / n)
+	avg.Distance /= n
+	avg.Speed /= n
+	avg.Calories /= n
+	return avg
+}
+
+// VsAverage возвращает разницу показателей тренировки относительно средних по истории.
+// Для пустой истории возвращает нулевую разницу.
+func VsAverage(training CaloriesCalculator, history []CaloriesCalculator) InfoDiff {
+	if len(history) == 0 {
+		return InfoDiff{}
+	}
+	return diffInfo(training.TrainingInfo(), averageInfo(history))
+}
+
+// Константы для оценки потребности в воде.
 const (
-	CaloriesMeanSpeedMultiplier = 18   // множитель средней скорости бега
-	CaloriesMeanSpeedShift      = 1.79 // коэффициент изменения средней скорости
+	WaterBaseMLPerHour = 400 // базовая потеря жидкости с потом в мл за час тренировки
+	WaterMLPerCalorie  = 0.5 // дополнительная потеря жидкости в мл на одну потраченную килокалорию
 )
 
-// Running структура, описывающая тренировку Бег.
-type Running struct {
-	// добавьте необходимые поля в структуру
-	...
+// RecommendedWaterML возвращает рекомендуемый объем воды в мл для восполнения потерь за тренировку.
+// Модель грубая: базовая скорость потоотделения за полную продолжительность тренировки,
+// так как потеря жидкости продолжается и во время пауз,
+// плюс надбавка, пропорциональная потраченным килокалориям.
+// Формула расчета:
+// WaterBaseMLPerHour * время_тренировки_в_часах + WaterMLPerCalorie * потраченные_ккал
+func RecommendedWaterML(training CaloriesCalculator) float64 {
+	info := training.TrainingInfo()
+	if info.Duration <= 0 {
+		return 0
+	}
+	return WaterBaseMLPerHour*info.Duration.Hours() + WaterMLPerCalorie*info.Calories
 }
 
-// Calories возввращает количество потраченных килокалория при беге.
-// Формула расчета:
-// ((18 * средняя_скорость_в_км/ч + 1.79) * вес_спортсмена_в_кг / м_в_км * время_тренировки_в_часах * мин_в_часе)
-// Это переопределенный метод Calories() из Training.
-func (r Running) Calories() float64 {
-	// вставьте ваш код ниже
-	...
+// Константы для оценки дожига калорий после тренировки (EPOC).
+const (
+	EPOCMinFraction          = 0.06 // доля EPOC от калорий тренировки при минимальной интенсивности
+	EPOCMaxFraction          = 0.15 // доля EPOC от калорий тренировки при максимальной интенсивности
+	EPOCMaxCaloriesPerMinute = 15   // расход ккал в минуту, соответствующий максимальной интенсивности
+)
+
+// EPOCCalories возвращает количество килокалорий, потраченных после тренировки (EPOC).
+// Модель упрощенная: EPOC составляет небольшую долю от калорий тренировки,
+// которая линейно растет от EPOCMinFraction до EPOCMaxFraction вместе с расходом ккал в минуту.
+func EPOCCalories(training CaloriesCalculator) float64 {
+	calories := training.Calories()
+	if calories <= 0 {
+		return 0
+	}
+	intensity := math.Min(burnRate(training)/EPOCMaxCaloriesPerMinute, 1)
+	return calories * (EPOCMinFraction + (EPOCMaxFraction-EPOCMinFraction)*intensity)
 }
 
-// TrainingInfo возвращает структуру InfoMessage с информацией о проведенной тренировке.
-// Это переопределенный метод TrainingInfo() из Training.
-func (r Running) TrainingInfo() InfoMessage {
-	// вставьте ваш код ниже
-	...
+// TotalWithEPOC возвращает количество килокалорий тренировки с учетом дожига после нее.
+func TotalWithEPOC(training CaloriesCalculator) float64 {
+	return training.Calories() + EPOCCalories(training)
 }
 
-// Константы для расчета потраченных килокалорий при ходьбе.
+// Категории интенсивности тренировки.
 const (
-	CaloriesWeightMultiplier      = 0.035 // коэффициент для веса
-	CaloriesSpeedHeightMultiplier = 0.029 // коэффициент для роста
-	KmHInMsec                     = 0.278 // коэффициент для перевода км/ч в м/с
+	IntensityLight    = "light"    // легкая
+	IntensityModerate = "moderate" // умеренная
+	IntensityVigorous = "vigorous" // высокая
 )
 
-// Walking структура описывающая тренировку Ходьба
-type Walking struct {
-	// добавьте необходимые поля в структуру
-	...
-	... // рост пользователя
+// Пороговые значения средней скорости в км/ч для категорий интенсивности.
+const (
+	RunningModerateSpeed  = 5   // бег со скоростью от 5 км/ч считается умеренным
+	RunningVigorousSpeed  = 8   // бег со скоростью от 8 км/ч считается интенсивным
+	WalkingModerateSpeed  = 4   // ходьба со скоростью от 4 км/ч считается умеренной
+	WalkingVigorousSpeed  = 6.5 // ходьба со скоростью от 6.5 км/ч считается интенсивной
+	SwimmingModerateSpeed = 1.5 // плавание со скоростью от 1.5 км/ч считается умеренным
+	SwimmingVigorousSpeed = 2.5 // плавание со скоростью от 2.5 км/ч считается интенсивным
+)
+
+// intensityCategory возвращает категорию интенсивности по средней скорости и пороговым значениям.
+func intensityCategory(speed, moderate, vigorous float64) string {
+	switch {
+	case speed >= vigorous:
+		return IntensityVigorous
+	case speed >= moderate:
+		return IntensityModerate
+	default:
+		return IntensityLight
+	}
 }
 
-// Calories возвращает количество потраченных килокалорий при ходьбе.
-// Формула расчета:
-// ((0.035 * вес_спортсмена_в_кг + (средняя_скорость_в_метрах_в_секунду**2 / рост_в_метрах)
-// * 0.029 * вес_спортсмена_в_кг) * время_тренировки_в_часах * мин_в_ч)
-// Это переопределенный метод Calories() из Training.
-func (w Walking) Calories() float64 {
-	// вставьте ваш код ниже
-	...
+// IntensityCategory возвращает категорию интенсивности тренировки по средней скорости
+// и пороговым значениям для ее типа. Тренировки неизвестного типа считаются легкими.
+func IntensityCategory(training CaloriesCalculator) string {
+	params, ok := paramsOf(training)
+	if !ok {
+		return IntensityLight
+	}
+	return intensityCategory(training.TrainingInfo().Speed, params.moderateSpeed, params.vigorousSpeed)
 }
 
-// TrainingInfo возвращает структуру InfoMessage с информацией о проведенной тренировке.
-// Это переопределенный метод TrainingInfo() из Training.
-func (w Walking) TrainingInfo() InfoMessage {
-	// вставьте ваш код ниже
-	...
+// DuplicateDistanceTolerance допустимая разница дистанций в км, при которой тренировки считаются дубликатами.
+const DuplicateDistanceTolerance = 0.05
+
+// IsDuplicate сообщает, описывают ли две тренировки одну и ту же сессию:
+// совпадает тип, длительность отличается не более чем на tol,
+// а дистанция не более чем на DuplicateDistanceTolerance.
+func IsDuplicate(a, b CaloriesCalculator, tol time.Duration) bool {
+	infoA, infoB := a.TrainingInfo(), b.TrainingInfo()
+	if infoA.TrainingType != infoB.TrainingType {
+		return false
+	}
+	diff := infoA.Duration - infoB.Duration
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= tol && math.Abs(infoA.Distance-infoB.Distance) <= DuplicateDistanceTolerance
 }
 
-// Константы для расчета потраченных килокалорий при плавании.
+// met возвращает среднюю интенсивность тренировки в MET:
+// количество килокалорий на килограмм веса в час движения.
+func met(training CaloriesCalculator) float64 {
+	moving := movingTimeOf(training)
+	weight := dataOf(training).Weight
+	if weight <= 0 || moving <= 0 {
+		return 0
+	}
+	return training.Calories() / weight / moving.Hours()
+}
+
+// EffortScore возвращает оценку нагрузки тренировки в MET-минутах.
+// Формула расчета:
+// время_движения_в_минутах * интенсивность_в_MET
+func EffortScore(training CaloriesCalculator) float64 {
+	return movingTimeOf(training).Minutes() * met(training)
+}
+
+// Типы активностей в формате внешних фитнес-сервисов.
 const (
-	SwimmingLenStep                  = 1.38 // длина одного гребка
-	SwimmingCaloriesMeanSpeedShift   = 1.1  // коэффициент изменения средней скорости
-	SwimmingCaloriesWeightMultiplier = 2    // множитель веса пользователя
+	ActivityRun  = "Run"
+	ActivityWalk = "Walk"
+	ActivitySwim = "Swim"
 )
 
-// Swimming структура, описывающая тренировку Плавание
-type Swimming struct {
-	// добавьте необходимые поля в структуру
-	...
-	... // длина бассейна
-	... // количество пересечений бассейна
+// Activity описывает тренировку в формате, принятом во внешних фитнес-сервисах.
+type Activity struct {
+	Name        string  `json:"name"`         // название тренировки
+	Type        string  `json:"type"`         // тип активности
+	ElapsedTime int     `json:"elapsed_time"` // продолжительность тренировки в секундах
+	Distance    float64 `json:"distance"`     // дистанция в метрах
+	Calories    float64 `json:"calories"`     // количество потраченных килокалорий
 }
 
-// meanSpeed возвращает среднюю скорость при плавании.
-// Формула расчета:
-// длина_бассейна * количество_пересечений / м_в_км / продолжительность_тренировки
-// Это переопределенный метод Calories() из Training.
-func (s Swimming) meanSpeed() float64 {
-	// вставьте ваш код ниже
-	...
+// ToActivity возвращает структуру Activity для тренировки.
+// Для тренировки неизвестного типа поле Type остается пустым.
+func ToActivity(training CaloriesCalculator) Activity {
+	info := training.TrainingInfo()
+	params, _ := paramsOf(training)
+	return Activity{
+		Name:        info.TrainingType,
+		Type:        params.activity,
+		ElapsedTime: int(info.Duration.Seconds()),
+		Distance:    info.Distance * MInKm,
+		Calories:    info.Calories,
+	}
 }
 
-// Calories возвращает количество калорий, потраченных при плавании.
+// CaloriesPerKmCalculator необязательный интерфейс для тренировок,
+// которые умеют сами рассчитывать расход килокалорий на километр.
+type CaloriesPerKmCalculator interface {
+	CaloriesPerKm() float64
+}
+
+// caloriesPerKm возвращает количество килокалорий на километр дистанции.
+// При нулевой дистанции возвращает 0.
+func caloriesPerKm(calories, distance float64) float64 {
+	if distance <= 0 {
+		return 0
+	}
+	return calories / distance
+}
+
+// AltitudeCaloriesFactor прирост расхода килокалорий на каждые 1000 м высоты над уровнем моря.
+const AltitudeCaloriesFactor = 0.02
+
+// CaloriesAtAltitude возвращает количество килокалорий с поправкой на высоту над уровнем моря.
 // Формула расчета:
-// (средняя_скорость_в_км/ч + SwimmingCaloriesMeanSpeedShift) * SwimmingCaloriesWeightMultiplier * вес_спортсмена_в_кг * время_тренировки_в_часах
-// Это переопределенный метод Calories() из Training.
-func (s Swimming) Calories() float64 {
-	// вставьте ваш код ниже
-	...
+// потраченные_ккал * (1 + AltitudeCaloriesFactor * высота_в_м / м_в_км)
+func CaloriesAtAltitude(training CaloriesCalculator, meters float64) float64 {
+	calories := training.Calories()
+	if meters <= 0 {
+		return calories
+	}
+	return calories * (1 + AltitudeCaloriesFactor*meters/MInKm)
 }
 
-// TrainingInfo returns info about swimming training.
-// Это переопределенный метод TrainingInfo() из Training.
-func (s Swimming) TrainingInfo() InfoMessage {
-	// вставьте ваш код ниже
-	...
+// CaloriesPerStairFlight количество килокалорий, расходуемых на подъем на один лестничный пролет.
+const CaloriesPerStairFlight = 1.5
+
+// EquivalentStairFlights возвращает количество лестничных пролетов, эквивалентное тренировке по калориям.
+func EquivalentStairFlights(training CaloriesCalculator) float64 {
+	return training.Calories() / CaloriesPerStairFlight
+}
+
+// Metric показатель тренировки, по которому сравниваются результаты.
+type Metric int
+
+// Показатели тренировки.
+const (
+	MetricDistance Metric = iota // дистанция в км
+	MetricDuration               // длительность в минутах
+	MetricSpeed                  // средняя скорость в км/ч
+	MetricCalories               // потраченные килокалории
+)
+
+// String возвращает название показателя.
+func (m Metric) String() string {
+	switch m {
+	case MetricDistance:
+		return "distance"
+	case MetricDuration:
+		return "duration"
+	case MetricSpeed:
+		return "speed"
+	case MetricCalories:
+		return "calories"
+	}
+	return fmt.Sprintf("Metric(%d)", int(m))
+}
+
+// value возвращает значение показателя из информации о тренировке.
+func (m Metric) value(info InfoMessage) float64 {
+	switch m {
+	case MetricDistance:
+		return info.Distance
+	case MetricDuration:
+		return info.Duration.Minutes()
+	case MetricSpeed:
+		return info.Speed
+	case MetricCalories:
+		return info.Calories
+	}
+	return 0
+}
+
+// IsPersonalBest сообщает, превосходит ли тренировка по показателю metric все тренировки из истории.
+// Для пустой истории любая тренировка считается рекордной.
+func IsPersonalBest(training CaloriesCalculator, history []CaloriesCalculator, metric Metric) bool {
+	current := metric.value(training.TrainingInfo())
+	for _, previous := range history {
+		if metric.value(previous.TrainingInfo()) >= current {
+			return false
+		}
+	}
+	return true
+}
+
+// Диапазоны средней скорости в км/ч, соответствующие интенсивности от 0 до 1.
+const (
+	RunningProxyMinSpeed  = 4   // бег с этой скоростью соответствует интенсивности 0
+	RunningProxyMaxSpeed  = 16  // бег с этой скоростью соответствует интенсивности 1
+	WalkingProxyMinSpeed  = 2   // ходьба с этой скоростью соответствует интенсивности 0
+	WalkingProxyMaxSpeed  = 8   // ходьба с этой скоростью соответствует интенсивности 1
+	SwimmingProxyMinSpeed = 0.5 // плавание с этой скоростью соответствует интенсивности 0
+	SwimmingProxyMaxSpeed = 4   // плавание с этой скоростью соответствует интенсивности 1
+)
+
+// intensityProxy возвращает оценку интенсивности от 0 до 1 по средней скорости.
+// Скорость линейно переводится из диапазона [minSpeed, maxSpeed] в [0, 1],
+// значения за пределами диапазона ограничиваются.
+func intensityProxy(speed, minSpeed, maxSpeed float64) float64 {
+	if maxSpeed <= minSpeed {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (speed-minSpeed)/(maxSpeed-minSpeed)))
+}
+
+// IntensityProxy возвращает оценку интенсивности тренировки от 0 до 1 по средней скорости
+// в диапазоне для ее типа. Для тренировки неизвестного типа возвращает 0.
+func IntensityProxy(training CaloriesCalculator) float64 {
+	params, ok := paramsOf(training)
+	if !ok {
+		return 0
+	}
+	return intensityProxy(training.TrainingInfo().Speed, params.proxyMinSpeed, params.proxyMaxSpeed)
+}
+
+// GlycogenKcalPerKg запас энергии в гликогене мышц и печени в ккал на килограмм веса.
+const GlycogenKcalPerKg = 25
+
+// GlycogenDepletionPercent возвращает долю запаса гликогена в процентах, израсходованную на тренировке.
+// Модель грубая: считается, что все калории тренировки берутся из гликогена,
+// запас которого пропорционален весу. Результат ограничен 100%.
+// Формула расчета:
+// потраченные_ккал / (GlycogenKcalPerKg * вес_спортсмена_в_кг) * 100
+func GlycogenDepletionPercent(training CaloriesCalculator) float64 {
+	weight := dataOf(training).Weight
+	if weight <= 0 {
+		return 0
+	}
+	return math.Min(100, training.Calories()/(GlycogenKcalPerKg*weight)*100)
+}
+
+// CadenceCalculator необязательный интерфейс для тренировок с шагами, которые умеют рассчитывать каденс.
+type CadenceCalculator interface {
+	Cadence() float64
+}
+
+// cadence возвращает каденс в шагах в минуту. При нулевой длительности возвращает 0.
+func cadence(steps int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(steps) / d.Minutes()
+}
+
+// CaloriesUncertainty относительная погрешность формул расчета килокалорий.
+// Формулы дают оценку, реальный расход может отличаться примерно на 15% в обе стороны.
+const CaloriesUncertainty = 0.15
+
+// CaloriesRange возвращает границы интервала, в который с учетом погрешности попадает расход килокалорий тренировки.
+func CaloriesRange(training CaloriesCalculator) (low, high float64) {
+	calories := training.Calories()
+	return calories * (1 - CaloriesUncertainty), calories * (1 + CaloriesUncertainty)
+}
+
+// Константы для оценки потери жидкости с потом.
+const (
+	SweatBaseLitersPerHour      = 0.5  // потеря жидкости в л/ч при минимальной интенсивности
+	SweatIntensityLitersPerHour = 1.0  // дополнительная потеря в л/ч при максимальной интенсивности
+	SweatComfortTemp            = 20   // температура воздуха в °C, при которой поправка не применяется
+	SweatTempFactor             = 0.03 // изменение потоотделения на каждый градус от комфортной температуры
+	SweatMinTempFactor          = 0.5  // минимальная температурная поправка в холодную погоду
+)
+
+// SweatLossLiters возвращает оценку потери жидкости с потом в литрах.
+// Модель грубая: скорость потоотделения растет линейно с интенсивностью
+// и меняется на SweatTempFactor на каждый градус отклонения от SweatComfortTemp.
+// Время берется полное, с паузами, так как потоотделение в них не прекращается.
+// Формула расчета:
+// (0.5 + 1.0 * интенсивность) * max(0.5, 1 + 0.03 * (температура - 20)) * время_тренировки_в_часах
+func SweatLossLiters(training CaloriesCalculator, tempC float64) float64 {
+	rate := SweatBaseLitersPerHour + SweatIntensityLitersPerHour*IntensityProxy(training)
+	tempFactor := math.Max(SweatMinTempFactor, 1+SweatTempFactor*(tempC-SweatComfortTemp))
+	return rate * tempFactor * training.TrainingInfo().Duration.Hours()
+}
+
+// smoothedMeanSpeed возвращает среднюю скорость по точкам трека после сглаживания скользящей медианой
+// с окном window точек, которое убирает одиночные выбросы GPS.
+// Если точек нет, возвращает fallback — среднюю скорость, рассчитанную по дистанции и времени.
+func smoothedMeanSpeed(points []float64, window int, fallback float64) float64 {
+	if len(points) == 0 {
+		return fallback
+	}
+	if window < 1 {
+		window = 1
+	}
+	if window > len(points) {
+		window = len(points)
+	}
+	var total float64
+	count := len(points) - window + 1
+	for i := 0; i < count; i++ {
+		total += median(points[i : i+window])
+	}
+	return total / float64(count)
+}
+
+// SmoothedMeanSpeed возвращает сглаженную среднюю скорость тренировки по точкам трека.
+// Без точек трека возвращает среднюю скорость из TrainingInfo.
+func SmoothedMeanSpeed(training CaloriesCalculator, window int) float64 {
+	return smoothedMeanSpeed(dataOf(training).SpeedPoints, window, training.TrainingInfo().Speed)
+}
+
+// BurnRateCurve возвращает расход килокалорий в минуту в points равноотстоящих точках тренировки.
+// При равномерной нагрузке кривая постоянна. Если записаны скорости в точках трека,
+// средний расход распределяется пропорционально скорости, и кривая отражает интервалы.
+// Точки равномерно распределены по полной продолжительности тренировки,
+// поэтому расход в них усредняется вместе с паузами.
+// При points <= 0 возвращает nil.
+func BurnRateCurve(training CaloriesCalculator, points int) []float64 {
+	if points <= 0 {
+		return nil
+	}
+	info := training.TrainingInfo()
+	speedPoints := dataOf(training).SpeedPoints
+	curve := make([]float64, points)
+	if info.Duration <= 0 {
+		return curve
+	}
+	rate := info.Calories / info.Duration.Minutes()
+
+	var meanSpeed float64
+	for _, speed := range speedPoints {
+		meanSpeed += speed
+	}
+	if len(speedPoints) > 0 {
+		meanSpeed /= float64(len(speedPoints))
+	}
+
+	for i := range curve {
+		curve[i] = rate
+		if meanSpeed > 0 {
+			curve[i] *= speedPoints[i*len(speedPoints)/points] / meanSpeed
+		}
+	}
+	return curve
+}
+
+// Константы для оценки времени восстановления.
+const (
+	RecoveryBaseHours         = 12   // минимальное время восстановления в часах
+	RecoveryHoursPerMETMinute = 0.05 // часы восстановления на одну MET-минуту нагрузки
+	RecoveryIntensityWeight   = 2    // усиление нагрузки при максимальной интенсивности
+	RecoveryMaxHours          = 72   // максимальное рекомендуемое время восстановления в часах
+)
+
+// RecommendedRecoveryHours возвращает рекомендуемое время отдыха после тренировки в часах.
+// Эвристика: к базовому времени добавляется время, пропорциональное нагрузке в MET-минутах
+// и усиленное интенсивностью тренировки. Результат ограничен RecoveryMaxHours.
+// Формула расчета:
+// 12 + 0.05 * нагрузка * (1 + 2 * интенсивность)
+func RecommendedRecoveryHours(training CaloriesCalculator) float64 {
+	hours := RecoveryBaseHours +
+		RecoveryHoursPerMETMinute*EffortScore(training)*(1+RecoveryIntensityWeight*IntensityProxy(training))
+	return math.Min(hours, RecoveryMaxHours)
+}
+
+// ShortCodeVersion версия формата короткого кода тренировки.
+const ShortCodeVersion = 2
+
+// Коды типов тренировок в коротком коде.
+const (
+	shortCodeRunning byte = iota + 1
+	shortCodeWalking
+	shortCodeSwimming
+)
+
+// Флаги общей части короткого кода.
+const (
+	shortCodeFatigue byte = 1 << iota // включена модель утомления
+	shortCodeDated                    // указана дата тренировки
+)
+
+// ErrShortCodeValue возвращается, если значение поля нельзя записать в короткий код тренировки.
+var ErrShortCodeValue = errors.New("недопустимое значение для кода тренировки")
+
+// shortCodeWriter записывает поля короткого кода в двоичном виде (big-endian).
+// Первая ошибка сохраняется в err, последующие записи игнорируются.
+type shortCodeWriter struct {
+	data []byte
+	err  error
+}
+
+// uint32 записывает неотрицательное целое поле name.
+func (w *shortCodeWriter) uint32(name string, v int) {
+	if w.err != nil {
+		return
+	}
+	if v < 0 || int64(v) > math.MaxUint32 {
+		w.err = fmt.Errorf("%w: %s = %d", ErrShortCodeValue, name, v)
+		return
+	}
+	w.data = binary.BigEndian.AppendUint32(w.data, uint32(v))
+}
+
+// float64 записывает вещественное поле.
+func (w *shortCodeWriter) float64(v float64) {
+	w.data = binary.BigEndian.AppendUint64(w.data, math.Float64bits(v))
+}
+
+// seconds записывает длительность поля name в целых секундах.
+func (w *shortCodeWriter) seconds(name string, d time.Duration) {
+	w.uint32(name, int(d/time.Second))
+}
+
+// encodeShortCode возвращает короткий код тренировки: поля в двоичном виде (big-endian)
+// с контрольной суммой CRC-32 в конце, закодированные в base64 без заполнения.
+// Общая часть: версия, тип, повторы, длина шага, длительность, вес, дата, пульс, дни без тренировок,
+// флаги и паузы. extra дописывает поля, специфичные для типа тренировки.
+// Отрицательные целые значения и длительности не записываются: возвращается ErrShortCodeValue.
+func encodeShortCode(kind byte, t Training, extra func(w *shortCodeWriter)) (string, error) {
+	w := shortCodeWriter{data: []byte{ShortCodeVersion, kind}}
+	w.uint32("Action", t.Action)
+	w.float64(t.LenStep)
+	w.seconds("Duration", t.Duration)
+	w.float64(t.Weight)
+
+	var flags byte
+	var date int64
+	if t.FatigueModel {
+		flags |= shortCodeFatigue
+	}
+	if !t.Date.IsZero() {
+		flags |= shortCodeDated
+		date = t.Date.Unix()
+	}
+	w.data = binary.BigEndian.AppendUint64(w.data, uint64(date))
+	w.uint32("AvgHeartRate", t.AvgHeartRate)
+	w.uint32("DaysOff", t.DaysOff)
+	w.data = append(w.data, flags)
+	w.uint32("Pauses", len(t.Pauses))
+	for _, pause := range t.Pauses {
+		w.seconds("Pauses", pause)
+	}
+	if extra != nil {
+		extra(&w)
+	}
+	if w.err != nil {
+		return "", w.err
+	}
+	data := binary.BigEndian.AppendUint32(w.data, crc32.ChecksumIEEE(w.data))
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// ToShortCode возвращает компактный код тренировки для передачи между устройствами, например через QR-код.
+// В код попадают все поля, влияющие на калории, а также дата и средний пульс;
+// пульсовые зоны и точки трека не записываются, чтобы код оставался коротким.
+// Для тренировки неизвестного типа или с отрицательными значениями возвращает ошибку.
+func ToShortCode(training CaloriesCalculator) (string, error) {
+	switch t := training.(type) {
+	case Running:
+		return encodeShortCode(shortCodeRunning, t.Training, func(w *shortCodeWriter) {
+			w.float64(t.ElevationGain)
+		})
+	case Walking:
+		return encodeShortCode(shortCodeWalking, t.Training, func(w *shortCodeWriter) {
+			w.float64(t.Height)
+			w.float64(t.ElevationGain)
+		})
+	case Swimming:
+		return encodeShortCode(shortCodeSwimming, t.Training, func(w *shortCodeWriter) {
+			w.uint32("LengthPool", t.LengthPool)
+			w.uint32("CountPool", t.CountPool)
+			w.uint32("StrokesPerLength", t.StrokesPerLength)
+			w.float64(t.PartialLength)
+		})
+	}
+	return "", fmt.Errorf("неизвестный тип тренировки %T", training)
+}
+
+// StepsPerCalorie количество шагов, эквивалентное одной килокалории, для активностей без шагов.
+const StepsPerCalorie = 20
+
+// EstimatedSteps возвращает количество шагов, сделанных на тренировке.
+// Для тренировок без шагов, например плавания, возвращает количество шагов, эквивалентное тренировке по калориям.
+func EstimatedSteps(training CaloriesCalculator) int {
+	switch t := training.(type) {
+	case Running:
+		return t.Action
+	case Walking:
+		return t.Action
+	}
+	return int(math.Round(training.Calories() * StepsPerCalorie))
+}
+
+// CaloriesPerDollar возвращает количество килокалорий тренировки на доллар стоимости занятия.
+// При неположительной стоимости возвращает 0.
+func CaloriesPerDollar(training CaloriesCalculator, sessionCost float64) float64 {
+	if sessionCost <= 0 {
+		return 0
+	}
+	return training.Calories() / sessionCost
+}
+
+// Константы модели нормированной нагрузки.
+const (
+	NormalizedEffortKcalPerMinute = 10  // условные ккал в минуту при максимальной интенсивности
+	NormalizedEffortBase          = 0.2 // базовая интенсивность, добавляемая к оценке по скорости
+)
+
+// NormalizedEffortCalories возвращает нормированную нагрузку тренировки в условных килокалориях,
+// не зависящую от веса пользователя: длительность, умноженная на интенсивность по скорости.
+// Базовая интенсивность учитывает, что даже легкая тренировка требует усилий,
+// поэтому долгая легкая и короткая тяжелая тренировки могут оцениваться одинаково.
+// Формула расчета:
+// время_движения_в_минутах * 10 * (0.2 + интенсивность)
+func NormalizedEffortCalories(training CaloriesCalculator) float64 {
+	minutes := movingTimeOf(training).Minutes()
+	return minutes * NormalizedEffortKcalPerMinute * (NormalizedEffortBase + IntensityProxy(training))
+}
+
+// PartialCalories возвращает количество килокалорий для тренировки, выполненной на долю completedFraction.
+// Доля ограничивается диапазоном от 0 до 1.
+func PartialCalories(training CaloriesCalculator, completedFraction float64) float64 {
+	return training.Calories() * math.Max(0, math.Min(1, completedFraction))
+}
+
+// Доля углеводов в энергообеспечении тренировки в процентах.
+const (
+	FuelMinCarbPercent = 30 // при минимальной интенсивности
+	FuelMaxCarbPercent = 90 // при максимальной интенсивности
+)
+
+// FuelMix возвращает доли жиров и углеводов в энергообеспечении тренировки в процентах.
+// Модель упрощенная: доля углеводов растет линейно с интенсивностью
+// от FuelMinCarbPercent до FuelMaxCarbPercent, остальное приходится на жиры.
+func FuelMix(training CaloriesCalculator) (fatPct, carbPct float64) {
+	carbPct = FuelMinCarbPercent + (FuelMaxCarbPercent-FuelMinCarbPercent)*IntensityProxy(training)
+	return 100 - carbPct, carbPct
+}
+
+// Параметры эталонной ходьбы для EquivalentWalkingKm.
+const (
+	WalkingReferenceSpeed  = 5   // скорость ходьбы в км/ч
+	WalkingReferenceHeight = 170 // рост пользователя в см
+)
+
+// EquivalentWalkingKm возвращает дистанцию ходьбы в км, на которой тратится столько же килокалорий, сколько на тренировке.
+// Расход на километр считается по формуле калорий ходьбы при том же весе. Для ходьбы берутся ее собственные
+// скорость и рост, поэтому результат близок к пройденной дистанции; для остальных тренировок —
+// WalkingReferenceSpeed и WalkingReferenceHeight.
+// Формула расчета:
+// потраченные_ккал / (ккал_ходьбы_в_час / скорость_ходьбы_в_км/ч)
+func EquivalentWalkingKm(training CaloriesCalculator) float64 {
+	weight := dataOf(training).Weight
+	if weight <= 0 {
+		return 0
+	}
+	speed, height := float64(WalkingReferenceSpeed), float64(WalkingReferenceHeight)
+	if w, ok := training.(Walking); ok {
+		if walkSpeed := w.meanSpeed(); walkSpeed > 0 {
+			speed = walkSpeed
+		}
+		if w.Height > 0 {
+			height = w.Height
+		}
+	}
+	return training.Calories() / (walkingCaloriesPerHour(weight, speed, height) / speed)
+}
+
+// EnergyDensity возвращает расход килокалорий тренировки на километр на килограмм веса.
+// При нулевом весе возвращает 0.
+// Формула расчета:
+// ккал_на_км / вес_спортсмена_в_кг
+func EnergyDensity(training CaloriesCalculator) float64 {
+	weight := dataOf(training).Weight
+	if weight <= 0 {
+		return 0
+	}
+	return CaloriesPerKm(training) / weight
+}
+
+// Константы для оценки расхода килокалорий в покое.
+const (
+	HoursInDay              = 24  // количество часов в сутках
+	SedentaryActivityFactor = 1.2 // коэффициент сидячего образа жизни к базовому обмену веществ
+)
+
+// ExtraCaloriesVsSedentary возвращает, на сколько килокалорий тренировка превысила расход
+// за то же время в сидячем положении при базовом обмене bmr ккал в сутки. Результат не меньше 0.
+// Сравнение идет за полную продолжительность тренировки, включая паузы.
+// Формула расчета:
+// потраченные_ккал - bmr / 24 * 1.2 * время_тренировки_в_часах
+func ExtraCaloriesVsSedentary(training CaloriesCalculator, bmr float64) float64 {
+	info := training.TrainingInfo()
+	sedentary := bmr / HoursInDay * SedentaryActivityFactor * info.Duration.Hours()
+	return math.Max(0, info.Calories-sedentary)
+}
+
+// ToFITFields возвращает поля тренировки с именами и единицами измерения из FIT SDK:
+// время в секундах, дистанция в метрах, скорость в м/с.
+// total_timer_time не включает паузы, в отличие от total_elapsed_time.
+func ToFITFields(training CaloriesCalculator) map[string]interface{} {
+	info := training.TrainingInfo()
+	params, _ := paramsOf(training)
+	return map[string]interface{}{
+		"sport":              params.fitSport,
+		"total_elapsed_time": info.Duration.Seconds(),
+		"total_timer_time":   movingTimeOf(training).Seconds(),
+		"total_distance":     info.Distance * MInKm,
+		"total_calories":     uint16(math.Min(math.Round(info.Calories), math.MaxUint16)),
+		"avg_speed":          info.Speed * MInKm / SecInHour,
+	}
+}
+
+// MarathonKm длина марафонской дистанции в км.
+const MarathonKm = 42.195
+
+// PercentOfMarathon возвращает дистанцию тренировки в процентах от марафонской.
+func PercentOfMarathon(training CaloriesCalculator) float64 {
+	return training.TrainingInfo().Distance / MarathonKm * 100
+}
+
+// Константы поправки расхода килокалорий на температуру воздуха.
+const (
+	CaloriesComfortTemp       = 20     // комфортная температура в °C, при которой поправка не применяется
+	CaloriesTemperatureFactor = 0.0005 // прирост расхода на квадрат отклонения от комфортной температуры
+)
+
+// CaloriesAtTemperature возвращает количество килокалорий тренировки с поправкой на температуру воздуха.
+// И холод, и жара повышают расход: поправка растет квадратично по мере удаления от комфортной температуры.
+// Формула расчета:
+// потраченные_ккал * (1 + 0.0005 * (температура - 20)^2)
+func CaloriesAtTemperature(training CaloriesCalculator, tempC float64) float64 {
+	deviation := tempC - CaloriesComfortTemp
+	return training.Calories() * (1 + CaloriesTemperatureFactor*deviation*deviation)
+}
+
+// Границы аэробной второй пульсовой зоны.
+const (
+	Zone2               = 2   // номер аэробной зоны в HRZoneMinutes
+	Zone2LowHRFraction  = 0.6 // нижняя граница зоны от максимального пульса
+	Zone2HighHRFraction = 0.7 // верхняя граница зоны от максимального пульса
+	Zone2LowIntensity   = 0.2 // нижняя граница зоны по оценке интенсивности
+	Zone2HighIntensity  = 0.5 // верхняя граница зоны по оценке интенсивности
+)
+
+// Zone2Minutes возвращает время тренировки во второй пульсовой зоне в минутах.
+// Если записано время хотя бы по одной зоне, используется оно: при отсутствии второй зоны
+// в записи возвращается 0. Иначе по среднему пульсу
+// или, если пульс не задан, по оценке интенсивности IntensityProxy определяется,
+// проходила ли вся тренировка во второй зоне.
+func Zone2Minutes(training CaloriesCalculator, maxHR int) float64 {
+	t := dataOf(training)
+	if len(t.HRZoneMinutes) > 0 {
+		return t.HRZoneMinutes[Zone2]
+	}
+	intensity := IntensityProxy(training)
+	inZone := intensity >= Zone2LowIntensity && intensity <= Zone2HighIntensity
+	if t.AvgHeartRate > 0 && maxHR > 0 {
+		fraction := float64(t.AvgHeartRate) / float64(maxHR)
+		inZone = fraction >= Zone2LowHRFraction && fraction <= Zone2HighHRFraction
+	}
+	if !inZone {
+		return 0
+	}
+	return movingTimeOf(training).Minutes()
+}
+
+// Константы для расчета потраченных килокалорий при беге.
+const (
+	CaloriesMeanSpeedMultiplier = 18   // множитель средней скорости бега
+	CaloriesMeanSpeedShift      = 1.79 // коэффициент изменения средней скорости
+)
+
+// Running структура, описывающая тренировку Бег.
+type Running struct {
+	Training
+	ElevationGain float64 // набор высоты за тренировку в м
+}
+
+// Calories возввращает количество потраченных килокалория при беге.
+// Формула расчета:
+// ((18 * средняя_скорость_в_км/ч + 1.79) * вес_спортсмена_в_кг / м_в_км * время_тренировки_в_часах * мин_в_часе)
+// Это переопределенный метод Calories() из Training.
+func (r Running) Calories() float64 {
+	calories := (CaloriesMeanSpeedMultiplier*r.meanSpeed() + CaloriesMeanSpeedShift) * r.Weight / MInKm * r.movingTime().Hours() * MinInHours
+	return r.adjustCalories(calories)
+}
+
+// TrainingInfo возвращает структуру InfoMessage с информацией о проведенной тренировке.
+// Это переопределенный метод TrainingInfo() из Training.
+func (r Running) TrainingInfo() InfoMessage {
+	info := r.Training.TrainingInfo()
+	info.Calories = r.Calories()
+	return info
+}
+
+// CaloriesPerKm возвращает количество килокалорий, потраченных на километр бега.
+func (r Running) CaloriesPerKm() float64 {
+	return caloriesPerKm(r.Calories(), r.distance())
+}
+
+// Cadence возвращает каденс бега в шагах в минуту.
+func (r Running) Cadence() float64 {
+	return cadence(r.Action, r.movingTime())
+}
+
+// Elevation возвращает набор высоты за тренировку бега в м.
+func (r Running) Elevation() float64 {
+	return r.ElevationGain
+}
+
+// RiegelExponent показатель степени в формуле Ригеля для прогноза времени на дистанции.
+const RiegelExponent = 1.06
+
+// PredictTime возвращает прогноз времени на дистанции targetKm по результату текущей тренировки.
+// Формула расчета (Ригель):
+// время_движения * (целевая_дистанция / дистанция_тренировки) ^ 1.06
+func (r Running) PredictTime(targetKm float64) time.Duration {
+	distance := r.distance()
+	if targetKm <= 0 || distance <= 0 {
+		return 0
+	}
+	return time.Duration(float64(r.movingTime()) * math.Pow(targetKm/distance, RiegelExponent))
+}
+
+// VsPacer возвращает отрыв от виртуального пейсера, бегущего с постоянным темпом paceMinPerKm мин/км.
+// Положительное значение означает, что пользователь финишировал раньше пейсера, отрицательное — позже.
+// Время берется полное, с паузами: пейсер не останавливается вместе с пользователем.
+// Формула расчета:
+// дистанция * темп_пейсера - время_тренировки
+func (r Running) VsPacer(paceMinPerKm float64) time.Duration {
+	if paceMinPerKm <= 0 {
+		return 0
+	}
+	pacer := time.Duration(r.distance() * paceMinPerKm * float64(time.Minute))
+	return pacer - r.Duration
+}
+
+// Коэффициенты поправки темпа на уклон.
+const (
+	GradeUphillFactor   = 0.033 // замедление темпа на каждый процент подъема
+	GradeDownhillFactor = 0.018 // ускорение темпа на каждый процент спуска
+	GradeDownhillLimit  = 10    // крутизна спуска в процентах, после которой ускорение не растет
+)
+
+// pace возвращает темп бега в мин/км по времени движения. При нулевой дистанции возвращает 0.
+func (r Running) pace() float64 {
+	distance := r.distance()
+	if distance <= 0 {
+		return 0
+	}
+	return r.movingTime().Minutes() / distance
+}
+
+// Grade возвращает средний уклон трассы в процентах по набору высоты.
+// Формула расчета:
+// набор_высоты_в_м / (дистанция * м_в_км) * 100
+func (r Running) Grade() float64 {
+	distance := r.distance()
+	if distance <= 0 {
+		return 0
+	}
+	return r.ElevationGain / (distance * MInKm) * 100
+}
+
+// GradeAdjustedPace возвращает темп в мин/км, приведенный к ровной поверхности, для уклона grade в процентах.
+// На подъеме каждый процент уклона замедляет бег на GradeUphillFactor,
+// на спуске каждый процент ускоряет на GradeDownhillFactor, но не круче GradeDownhillLimit.
+// Формула расчета:
+// темп / (1 + GradeUphillFactor * уклон) на подъеме,
+// темп / (1 - GradeDownhillFactor * |уклон|) на спуске
+func (r Running) GradeAdjustedPace(grade float64) float64 {
+	pace := r.pace()
+	if grade >= 0 {
+		return pace / (1 + GradeUphillFactor*grade)
+	}
+	return pace / (1 - GradeDownhillFactor*math.Min(-grade, GradeDownhillLimit))
+}
+
+// RequiredPace возвращает темп в мин/км, необходимый для преодоления дистанции targetDistanceKm за время targetTime.
+// При неположительных значениях возвращает 0.
+func RequiredPace(targetDistanceKm float64, targetTime time.Duration) float64 {
+	if targetDistanceKm <= 0 || targetTime <= 0 {
+		return 0
+	}
+	return targetTime.Minutes() / targetDistanceKm
+}
+
+// Скорости в км/ч, близкие к мировым рекордам на 5 км, — эталон для возрастной оценки.
+// Эталон задан только для 5 км, поэтому оценка считается лишь для дистанций
+// от AgeGradeMinDistance до AgeGradeMaxDistance км.
+const (
+	AgeGradeMaleOpenSpeed   = 23.84 // 12:35 на 5 км
+	AgeGradeFemaleOpenSpeed = 21.43 // 14:00 на 5 км
+	AgeGradeMinDistance     = 4.5
+	AgeGradeMaxDistance     = 5.5
+)
+
+// ageGradeFactors приближенные возрастные коэффициенты для дистанции 5 км:
+// доля эталонной скорости, доступная спортсмену начиная с указанного возраста.
+// Значения не взяты из какого-либо издания таблиц WMA (World Masters Athletics),
+// а лишь примерно повторяют их общий ход, поэтому результат годится только для грубой оценки.
+var ageGradeFactors = []struct {
+	age    int
+	factor float64
+}{
+	{0, 1.0},
+	{35, 0.99},
+	{40, 0.96},
+	{45, 0.93},
+	{50, 0.90},
+	{55, 0.87},
+	{60, 0.84},
+	{65, 0.81},
+	{70, 0.77},
+	{75, 0.73},
+	{80, 0.68},
+}
+
+// ageGradeFactor возвращает возрастной коэффициент для возраста age.
+func ageGradeFactor(age int) float64 {
+	factor := ageGradeFactors[0].factor
+	for _, row := range ageGradeFactors {
+		if age < row.age {
+			break
+		}
+		factor = row.factor
+	}
+	return factor
+}
+
+// AgeGradedPercent возвращает результат тренировки в процентах от эталона на 5 км для возраста и пола спортсмена.
+// Для дистанций вне диапазона от AgeGradeMinDistance до AgeGradeMaxDistance км возвращает 0.
+// Формула расчета:
+// средняя_скорость / (эталонная_скорость * возрастной_коэффициент) * 100
+func (r Running) AgeGradedPercent(age int, isMale bool) float64 {
+	distance := r.distance()
+	if age <= 0 || distance < AgeGradeMinDistance || distance > AgeGradeMaxDistance {
+		return 0
+	}
+	openSpeed := AgeGradeFemaleOpenSpeed
+	if isMale {
+		openSpeed = AgeGradeMaleOpenSpeed
+	}
+	return r.meanSpeed() / (openSpeed * ageGradeFactor(age)) * 100
+}
+
+// PercentOfThreshold возвращает интенсивность бега в процентах от порогового темпа thresholdPace в мин/км.
+// Значение больше 100 означает бег быстрее порогового темпа.
+// Формула расчета:
+// пороговый_темп / темп_тренировки * 100
+func (r Running) PercentOfThreshold(thresholdPace float64) float64 {
+	pace := r.pace()
+	if thresholdPace <= 0 || pace <= 0 {
+		return 0
+	}
+	return thresholdPace / pace * 100
+}
+
+// PredictWithFade возвращает прогноз времени на дистанции targetKm с учетом замедления к финишу.
+// В отличие от формулы Ригеля темп не постоянный: он линейно замедляется от текущего темпа тренировки на старте
+// до темпа, медленнее на fadePercent процентов, на финише. Отрицательное замедление считается нулевым.
+// Формула расчета:
+// темп * целевая_дистанция * (1 + замедление / 200)
+func (r Running) PredictWithFade(targetKm, fadePercent float64) time.Duration {
+	pace := r.pace()
+	if targetKm <= 0 || pace <= 0 {
+		return 0
+	}
+	fade := math.Max(0, fadePercent) / 100
+	return time.Duration(pace * targetKm * (1 + fade/2) * float64(time.Minute))
+}
+
+// PaceTargets возвращает время, которое нужно показать на каждой отметке дистанции distances в км,
+// чтобы пробежать totalKm км за targetTime в равномерном темпе.
+// Отметки вне диапазона (0, totalKm] пропускаются. При неположительных targetTime или totalKm возвращает nil.
+// Формула расчета:
+// целевое_время * отметка / общая_дистанция
+func PaceTargets(distances []float64, targetTime time.Duration, totalKm float64) map[float64]time.Duration {
+	if targetTime <= 0 || totalKm <= 0 {
+		return nil
+	}
+	targets := make(map[float64]time.Duration, len(distances))
+	for _, distance := range distances {
+		if distance <= 0 || distance > totalKm {
+			continue
+		}
+		targets[distance] = time.Duration(float64(targetTime) * distance / totalKm)
+	}
+	return targets
+}
+
+// Константы для расчета потраченных килокалорий при ходьбе.
+const (
+	CaloriesWeightMultiplier      = 0.035 // коэффициент для веса
+	CaloriesSpeedHeightMultiplier = 0.029 // коэффициент для роста
+	KmHInMsec                     = 0.278 // коэффициент для перевода км/ч в м/с
+)
+
+// Walking структура описывающая тренировку Ходьба
+type Walking struct {
+	Training
+	Height        float64 // рост пользователя
+	ElevationGain float64 // набор высоты за тренировку в м
+}
+
+// Calories возвращает количество потраченных килокалорий при ходьбе.
+// Формула расчета:
+// ((0.035 * вес_спортсмена_в_кг + (средняя_скорость_в_метрах_в_секунду**2 / рост_в_метрах)
+// * 0.029 * вес_спортсмена_в_кг) * время_тренировки_в_часах * мин_в_ч)
+// Это переопределенный метод Calories() из Training.
+func (w Walking) Calories() float64 {
+	if w.Height <= 0 {
+		return w.adjustCalories(0)
+	}
+	calories := walkingCaloriesPerHour(w.Weight, w.meanSpeed(), w.Height) * w.movingTime().Hours()
+	return w.adjustCalories(calories)
+}
+
+// walkingCaloriesPerHour возвращает расход килокалорий за час ходьбы
+// при весе weight кг, скорости speedKmh км/ч и росте height см.
+// Формула расчета:
+// (0.035 * вес_спортсмена_в_кг + (средняя_скорость_в_метрах_в_секунду**2 / рост_в_метрах)
+// * 0.029 * вес_спортсмена_в_кг) * мин_в_часе
+func walkingCaloriesPerHour(weight, speedKmh, height float64) float64 {
+	speed := speedKmh * KmHInMsec
+	return (CaloriesWeightMultiplier*weight + (math.Pow(speed, 2)/(height/CmInM))*CaloriesSpeedHeightMultiplier*weight) * MinInHours
+}
+
+// TrainingInfo возвращает структуру InfoMessage с информацией о проведенной тренировке.
+// Это переопределенный метод TrainingInfo() из Training.
+func (w Walking) TrainingInfo() InfoMessage {
+	info := w.Training.TrainingInfo()
+	info.Calories = w.Calories()
+	return info
+}
+
+// CaloriesPerKm возвращает количество килокалорий, потраченных на километр ходьбы.
+func (w Walking) CaloriesPerKm() float64 {
+	return caloriesPerKm(w.Calories(), w.distance())
+}
+
+// Cadence возвращает каденс ходьбы в шагах в минуту.
+func (w Walking) Cadence() float64 {
+	return cadence(w.Action, w.movingTime())
+}
+
+// Elevation возвращает набор высоты за тренировку ходьбы в м.
+func (w Walking) Elevation() float64 {
+	return w.ElevationGain
+}
+
+// Константы для расчета потраченных килокалорий при плавании.
+const (
+	SwimmingLenStep                  = 1.38 // длина одного гребка
+	SwimmingCaloriesMeanSpeedShift   = 1.1  // коэффициент изменения средней скорости
+	SwimmingCaloriesWeightMultiplier = 2    // множитель веса пользователя
+)
+
+// Swimming структура, описывающая тренировку Плавание
+type Swimming struct {
+	Training
+	LengthPool       int     // длина бассейна
+	CountPool        int     // количество пересечений бассейна
+	StrokesPerLength int     // количество гребков за одну длину бассейна
+	PartialLength    float64 // проплытая доля неполной последней длины бассейна (от 0 до 1)
+}
+
+// poolDistance возвращает дистанцию в метрах, проплытую в бассейне, с учетом неполной длины.
+// Формула расчета:
+// длина_бассейна * (количество_пересечений + доля_неполной_длины)
+func (s Swimming) poolDistance() float64 {
+	return float64(s.LengthPool) * (float64(s.CountPool) + s.PartialLength)
+}
+
+// meanSpeed возвращает среднюю скорость при плавании.
+// Формула расчета:
+// длина_бассейна * количество_пересечений / м_в_км / продолжительность_тренировки
+// Это переопределенный метод Calories() из Training.
+func (s Swimming) meanSpeed() float64 {
+	moving := s.movingTime()
+	if moving <= 0 {
+		return 0
+	}
+	return s.poolDistance() / MInKm / moving.Hours()
+}
+
+// Calories возвращает количество калорий, потраченных при плавании.
+// Формула расчета:
+// (средняя_скорость_в_км/ч + SwimmingCaloriesMeanSpeedShift) * SwimmingCaloriesWeightMultiplier * вес_спортсмена_в_кг * время_тренировки_в_часах
+// Это переопределенный метод Calories() из Training.
+func (s Swimming) Calories() float64 {
+	calories := (s.meanSpeed() + SwimmingCaloriesMeanSpeedShift) * SwimmingCaloriesWeightMultiplier * s.Weight * s.movingTime().Hours()
+	return s.adjustCalories(calories)
+}
+
+// TrainingInfo returns info about swimming training.
+// Это переопределенный метод TrainingInfo() из Training.
+func (s Swimming) TrainingInfo() InfoMessage {
+	info := s.Training.TrainingInfo()
+	info.Speed = s.meanSpeed()
+	info.Calories = s.Calories()
+	return info
+}
+
+// CaloriesPerKm возвращает количество килокалорий, потраченных на километр дистанции в бассейне.
+func (s Swimming) CaloriesPerKm() float64 {
+	return caloriesPerKm(s.Calories(), s.poolDistance()/MInKm)
+}
+
+// SWOLF возвращает показатель эффективности плавания:
+// сумму гребков и секунд, затраченных на одну длину бассейна.
+// Неполная последняя длина учитывается своей долей PartialLength.
+// Формула расчета:
+// гребки_за_длину + время_движения_в_секундах / (количество_пересечений + доля_неполной_длины)
+func (s Swimming) SWOLF() float64 {
+	lengths := float64(s.CountPool) + s.PartialLength
+	if lengths <= 0 {
+		return 0
+	}
+	return float64(s.StrokesPerLength) + s.movingTime().Seconds()/lengths
+}
+
+// NormalizeToPoolLength возвращает ту же тренировку, пересчитанную на бассейн длиной targetLen м.
+// Общая дистанция сохраняется: если она не делится на длину бассейна нацело, остаток записывается в PartialLength.
+func (s Swimming) NormalizeToPoolLength(targetLen int) Swimming {
+	if targetLen <= 0 {
+		return s
+	}
+	lengths := s.poolDistance() / float64(targetLen)
+	full := math.Floor(lengths)
+	s.LengthPool = targetLen
+	s.CountPool = int(full)
+	s.PartialLength = lengths - full
+	return s
+}
+
+// OpenWaterAdjustedDistance возвращает дистанцию, которую пользователь проплыл своими силами, в км
+// с поправкой на течение. currentFactor больше 1 означает попутное течение: оно добавляет к измеренной
+// дистанции путь, пройденный вместе с водой, поэтому результат меньше измеренной дистанции.
+// currentFactor меньше 1 означает встречное течение, и результат больше измеренной дистанции.
+// При currentFactor = 1 или неположительном значении возвращается исходная дистанция.
+// Формула расчета:
+// дистанция / коэффициент_течения
+func (s Swimming) OpenWaterAdjustedDistance(currentFactor float64) float64 {
+	if currentFactor <= 0 {
+		return s.distance()
+	}
+	return s.distance() / currentFactor
+}
+
+// OvertrainingRiskRatio граница соотношения острой и хронической нагрузки,
+// выше которой риск перетренированности считается высоким.
+const OvertrainingRiskRatio = 1.5
+
+// AcuteChronicRatio возвращает соотношение нагрузки текущей недели
+// к средней недельной нагрузке за последние четыре недели (калории или дистанция).
+// Формула расчета:
+// нагрузка_текущей_недели / средняя_нагрузка_за_4_недели
+func AcuteChronicRatio(thisWeek, last4WeeksAvg float64) float64 {
+	if last4WeeksAvg <= 0 {
+		return 0
+	}
+	return thisWeek / last4WeeksAvg
+}
+
+// IsOvertrainingRisk сообщает, превышает ли рост недельной нагрузки безопасный порог.
+func IsOvertrainingRisk(thisWeek, last4WeeksAvg float64) bool {
+	return AcuteChronicRatio(thisWeek, last4WeeksAvg) > OvertrainingRiskRatio
+}
+
+// totalCalories возвращает суммарное количество килокалорий, потраченных на тренировках.
+func totalCalories(trainings []CaloriesCalculator) float64 {
+	var total float64
+	for _, training := range trainings {
+		total += training.Calories()
+	}
+	return total
+}
+
+// PlanDelta возвращает разницу суммарных килокалорий между планами тренировок a и b.
+// Положительное значение означает, что план a расходует больше килокалорий.
+func PlanDelta(a, b []CaloriesCalculator) float64 {
+	return totalCalories(a) - totalCalories(b)
+}
+
+// CountByIntensity возвращает количество тренировок в каждой категории интенсивности.
+func CountByIntensity(trainings []CaloriesCalculator) map[string]int {
+	counts := make(map[string]int)
+	for _, training := range trainings {
+		counts[IntensityCategory(training)]++
+	}
+	return counts
+}
+
+// Profile содержит данные пользователя, общие для всех его тренировок.
+type Profile struct {
+	Weight float64 // вес пользователя в кг
+	Height float64 // рост пользователя в см
+}
+
+// training возвращает структуру Training, заполненную данными профиля.
+func (p Profile) training(trainingType string, action int, lenStep float64, d time.Duration) Training {
+	return Training{
+		TrainingType: trainingType,
+		Action:       action,
+		LenStep:      lenStep,
+		Duration:     d,
+		Weight:       p.Weight,
+	}
+}
+
+// NewRunning возвращает тренировку Бег с данными профиля.
+func (p Profile) NewRunning(action int, d time.Duration) Running {
+	return Running{
+		Training: p.training(RunningType, action, LenStep, d),
+	}
+}
+
+// NewWalking возвращает тренировку Ходьба с данными профиля.
+func (p Profile) NewWalking(action int, d time.Duration) Walking {
+	return Walking{
+		Training: p.training(WalkingType, action, LenStep, d),
+		Height:   p.Height,
+	}
+}
+
+// NewSwimming возвращает тренировку Плавание с данными профиля.
+func (p Profile) NewSwimming(action int, d time.Duration, lengthPool, countPool int) Swimming {
+	return Swimming{
+		Training:   p.training(SwimmingType, action, SwimmingLenStep, d),
+		LengthPool: lengthPool,
+		CountPool:  countPool,
+	}
+}
+
+// Dedup возвращает список тренировок без дубликатов, сохраняя первое вхождение каждой сессии.
+func Dedup(trainings []CaloriesCalculator, tol time.Duration) []CaloriesCalculator {
+	result := make([]CaloriesCalculator, 0, len(trainings))
+	for _, training := range trainings {
+		duplicate := false
+		for _, kept := range result {
+			if IsDuplicate(kept, training, tol) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			result = append(result, training)
+		}
+	}
+	return result
+}
+
+// dayStart возвращает начало дня для момента времени t.
+func dayStart(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// locationOrUTC возвращает часовой пояс loc или UTC, если loc не задан.
+func locationOrUTC(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// CurrentStreak возвращает количество дней подряд с хотя бы одной тренировкой, заканчивающихся сегодня.
+// Если сегодня тренировки еще не было, серия отсчитывается от вчерашнего дня.
+// Дни определяются в часовом поясе пользователя loc, чтобы поздняя тренировка попала в свой местный день;
+// при loc = nil используется UTC.
+func CurrentStreak(dates []time.Time, loc *time.Location) int {
+	return currentStreak(dates, time.Now(), loc)
+}
+
+// currentStreak возвращает серию дней с тренировками относительно момента now в часовом поясе loc.
+func currentStreak(dates []time.Time, now time.Time, loc *time.Location) int {
+	loc = locationOrUTC(loc)
+	now = now.In(loc)
+	days := make(map[time.Time]bool, len(dates))
+	for _, date := range dates {
+		days[dayStart(date.In(loc))] = true
+	}
+	day := dayStart(now)
+	if !days[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	streak := 0
+	for days[day] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// CaloriesPerKm возвращает количество килокалорий на километр для любой тренировки.
+// Если тренировка реализует CaloriesPerKmCalculator, используется ее собственный расчет,
+// иначе дистанция берется из TrainingInfo.
+func CaloriesPerKm(training CaloriesCalculator) float64 {
+	if calculator, ok := training.(CaloriesPerKmCalculator); ok {
+		return calculator.CaloriesPerKm()
+	}
+	info := training.TrainingInfo()
+	return caloriesPerKm(info.Calories, info.Distance)
+}
+
+// TimeWeightedMeanSpeed возвращает среднюю скорость по тренировкам, взвешенную по их длительности.
+// В отличие от простого среднего, где короткая и длинная тренировки весят одинаково,
+// здесь каждая скорость учитывается пропорционально времени тренировки.
+// Формула расчета:
+// сумма(скорость * длительность) / сумма(длительность)
+func TimeWeightedMeanSpeed(trainings []CaloriesCalculator) float64 {
+	var weighted, total float64
+	for _, training := range trainings {
+		info := training.TrainingInfo()
+		weighted += info.Speed * info.Duration.Hours()
+		total += info.Duration.Hours()
+	}
+	if total <= 0 {
+		return 0
+	}
+	return weighted / total
+}
+
+// validateTraining возвращает ошибку, если данные тренировки некорректны.
+func validateTraining(training CaloriesCalculator) error {
+	if training == nil {
+		return errors.New("тренировка не задана")
+	}
+	info := training.TrainingInfo()
+	switch {
+	case info.TrainingType == "":
+		return errors.New("не указан тип тренировки")
+	case info.Duration <= 0:
+		return fmt.Errorf("некорректная длительность %v", info.Duration)
+	case info.Distance < 0 || math.IsNaN(info.Distance) || math.IsInf(info.Distance, 0):
+		return fmt.Errorf("некорректная дистанция %v", info.Distance)
+	case info.Calories < 0 || math.IsNaN(info.Calories) || math.IsInf(info.Calories, 0):
+		return fmt.Errorf("некорректное количество килокалорий %v", info.Calories)
+	}
+	for _, pause := range dataOf(training).Pauses {
+		if pause < 0 {
+			return fmt.Errorf("некорректная пауза %v", pause)
+		}
+	}
+	return nil
+}
+
+// Sanitize возвращает список корректных тренировок и сообщения о причинах удаления остальных.
+func Sanitize(trainings []CaloriesCalculator) ([]CaloriesCalculator, []string) {
+	valid := make([]CaloriesCalculator, 0, len(trainings))
+	var messages []string
+	for i, training := range trainings {
+		if err := validateTraining(training); err != nil {
+			messages = append(messages, fmt.Sprintf("тренировка %d удалена: %v", i, err))
+			continue
+		}
+		valid = append(valid, training)
+	}
+	return valid, messages
+}
+
+// median возвращает медиану значений. Для пустого списка возвращает 0.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// medianInfo возвращает медиану показателя, выбранного функцией field, по списку тренировок.
+func medianInfo(trainings []CaloriesCalculator, field func(InfoMessage) float64) float64 {
+	values := make([]float64, 0, len(trainings))
+	for _, training := range trainings {
+		values = append(values, field(training.TrainingInfo()))
+	}
+	return median(values)
+}
+
+// MedianCalories возвращает медиану потраченных килокалорий по тренировкам.
+func MedianCalories(trainings []CaloriesCalculator) float64 {
+	return medianInfo(trainings, func(info InfoMessage) float64 { return info.Calories })
+}
+
+// MedianDistance возвращает медиану дистанции в км по тренировкам.
+func MedianDistance(trainings []CaloriesCalculator) float64 {
+	return medianInfo(trainings, func(info InfoMessage) float64 { return info.Distance })
+}
+
+// MedianSpeed возвращает медиану средней скорости в км/ч по тренировкам.
+func MedianSpeed(trainings []CaloriesCalculator) float64 {
+	return medianInfo(trainings, func(info InfoMessage) float64 { return info.Speed })
+}
+
+// TotalZoneMinutes возвращает суммарное время в минутах по пульсовым зонам для всех тренировок.
+func TotalZoneMinutes(trainings []CaloriesCalculator) map[int]float64 {
+	total := make(map[int]float64)
+	for _, training := range trainings {
+		for zone, minutes := range dataOf(training).HRZoneMinutes {
+			total[zone] += minutes
+		}
+	}
+	return total
+}
+
+// trainingConfig содержит параметры тренировки, собираемые функциональными опциями.
+type trainingConfig struct {
+	training   Training
+	height     float64
+	lengthPool int
+	countPool  int
+}
+
+// Option задает параметр тренировки для New.
+type Option func(*trainingConfig)
+
+// WithType задает тип тренировки: RunningType, WalkingType или SwimmingType.
+func WithType(trainingType string) Option {
+	return func(c *trainingConfig) { c.training.TrainingType = trainingType }
+}
+
+// WithAction задает количество повторов (шагов или гребков).
+func WithAction(action int) Option {
+	return func(c *trainingConfig) { c.training.Action = action }
+}
+
+// WithLenStep задает длину шага или гребка в м.
+// Если не задана, используется значение по умолчанию для типа тренировки.
+func WithLenStep(lenStep float64) Option {
+	return func(c *trainingConfig) { c.training.LenStep = lenStep }
+}
+
+// WithDuration задает продолжительность тренировки.
+func WithDuration(d time.Duration) Option {
+	return func(c *trainingConfig) { c.training.Duration = d }
+}
+
+// WithWeight задает вес пользователя в кг.
+func WithWeight(weight float64) Option {
+	return func(c *trainingConfig) { c.training.Weight = weight }
+}
+
+// WithHeight задает рост пользователя в см (для ходьбы).
+func WithHeight(height float64) Option {
+	return func(c *trainingConfig) { c.height = height }
+}
+
+// WithPool задает длину бассейна и количество его пересечений (для плавания).
+func WithPool(lengthPool, countPool int) Option {
+	return func(c *trainingConfig) {
+		c.lengthPool = lengthPool
+		c.countPool = countPool
+	}
+}
+
+// New возвращает тренировку, собранную из функциональных опций.
+// Тип тренировки задается опцией WithType.
+func New(opts ...Option) (CaloriesCalculator, error) {
+	var c trainingConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	switch c.training.TrainingType {
+	case RunningType:
+		if c.training.LenStep == 0 {
+			c.training.LenStep = LenStep
+		}
+		return Running{Training: c.training}, nil
+	case WalkingType:
+		if c.training.LenStep == 0 {
+			c.training.LenStep = LenStep
+		}
+		return Walking{Training: c.training, Height: c.height}, nil
+	case SwimmingType:
+		if c.training.LenStep == 0 {
+			c.training.LenStep = SwimmingLenStep
+		}
+		return Swimming{Training: c.training, LengthPool: c.lengthPool, CountPool: c.countPool}, nil
+	}
+	return nil, fmt.Errorf("неизвестный тип тренировки %q", c.training.TrainingType)
+}
+
+// RestDayBurn возвращает количество килокалорий, расходуемых за сутки без тренировок,
+// по базовому обмену веществ bmr (ккал в сутки).
+func RestDayBurn(bmr float64) float64 {
+	if bmr < 0 {
+		return 0
+	}
+	return bmr
+}
+
+// DailyTotalBurn возвращает суммарный расход килокалорий за день:
+// базовый обмен веществ плюс калории всех тренировок дня.
+func DailyTotalBurn(bmr float64, sessions []CaloriesCalculator) float64 {
+	return RestDayBurn(bmr) + totalCalories(sessions)
+}
+
+// escapeMarkdownCell экранирует символы, нарушающие разметку ячейки markdown-таблицы.
+func escapeMarkdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
+
+// ExportMarkdown записывает в w журнал тренировок в виде markdown-таблицы.
+func ExportMarkdown(w io.Writer, trainings []CaloriesCalculator) error {
+	if _, err := fmt.Fprint(w, "| Тип | Длительность, мин | Дистанция, км | Ккал |\n|---|---|---|---|\n"); err != nil {
+		return err
+	}
+	for _, training := range trainings {
+		info := training.TrainingInfo()
+		_, err := fmt.Fprintf(w, "| %s | %v | %s | %s |\n",
+			escapeMarkdownCell(info.TrainingType),
+			info.Duration.Minutes(),
+			formatFloat(info.Distance),
+			formatFloat(info.Calories),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AtWeight возвращает копию тренировки с другим весом пользователя.
+// Тренировка неизвестного типа возвращается без изменений.
+func AtWeight(training CaloriesCalculator, weight float64) CaloriesCalculator {
+	switch t := training.(type) {
+	case Running:
+		t.Weight = weight
+		return t
+	case Walking:
+		t.Weight = weight
+		return t
+	case Swimming:
+		t.Weight = weight
+		return t
+	}
+	return training
+}
+
+// ReplayAtWeight возвращает суммарное количество килокалорий, которое было бы потрачено
+// на тренировках при весе weight. Исходные тренировки не изменяются.
+func ReplayAtWeight(trainings []CaloriesCalculator, weight float64) float64 {
+	var total float64
+	for _, training := range trainings {
+		total += AtWeight(training, weight).Calories()
+	}
+	return total
+}
+
+// ElevationCalculator необязательный интерфейс для тренировок, в которых учитывается набор высоты.
+type ElevationCalculator interface {
+	Elevation() float64
+}
+
+// TotalElevationGain возвращает суммарный набор высоты в м по тренировкам, реализующим ElevationCalculator.
+func TotalElevationGain(trainings []CaloriesCalculator) float64 {
+	var total float64
+	for _, training := range trainings {
+		if calculator, ok := training.(ElevationCalculator); ok {
+			total += calculator.Elevation()
+		}
+	}
+	return total
+}
+
+// RollingBests возвращает лучшее на текущий момент значение показателя metric после каждой тренировки.
+// Тренировки должны быть упорядочены по времени.
+func RollingBests(trainings []CaloriesCalculator, metric Metric) []float64 {
+	bests := make([]float64, 0, len(trainings))
+	for i, training := range trainings {
+		value := metric.value(training.TrainingInfo())
+		if i > 0 && bests[i-1] > value {
+			value = bests[i-1]
+		}
+		bests = append(bests, value)
+	}
+	return bests
+}
+
+// AverageCadence возвращает средний каденс по тренировкам, реализующим CadenceCalculator.
+// Остальные тренировки, например плавание, не учитываются.
+// Если подходящих тренировок нет, возвращает 0.
+func AverageCadence(trainings []CaloriesCalculator) float64 {
+	var total float64
+	var count int
+	for _, training := range trainings {
+		if calculator, ok := training.(CadenceCalculator); ok {
+			total += calculator.Cadence()
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// VarietyScore возвращает оценку разнообразия тренировок от 0 до 1:
+// нормированную энтропию Шеннона распределения тренировок по типам.
+// Чем равномернее представлены типы, тем выше оценка; для одного типа она равна 0.
+// Формула расчета:
+// -сумма(p * ln(p)) / ln(количество_типов), где p — доля тренировок типа
+func VarietyScore(trainings []CaloriesCalculator) float64 {
+	counts := make(map[string]int)
+	for _, training := range trainings {
+		counts[training.TrainingInfo().TrainingType]++
+	}
+	if len(counts) < 2 {
+		return 0
+	}
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(len(trainings))
+		entropy -= p * math.Log(p)
+	}
+	return entropy / math.Log(float64(len(counts)))
+}
+
+// EpleyRepsDivisor делитель количества повторений в формуле Эпли.
+const EpleyRepsDivisor = 30
+
+// OneRepMaxEstimate возвращает оценку максимального веса на одно повторение по формуле Эпли.
+// При неположительных значениях возвращает 0.
+// Формула расчета:
+// вес * (1 + повторения / 30)
+func OneRepMaxEstimate(weight, reps float64) float64 {
+	if weight <= 0 || reps <= 0 {
+		return 0
+	}
+	return weight * (1 + reps/EpleyRepsDivisor)
+}
+
+// WeeklyCaloriesByDay возвращает суммарное количество килокалорий за каждый день недели.
+// Для дней без тренировок возвращает 0.
+func WeeklyCaloriesByDay(days [7][]CaloriesCalculator) [7]float64 {
+	var totals [7]float64
+	for i, day := range days {
+		totals[i] = totalCalories(day)
+	}
+	return totals
+}
+
+// AdherenceDistanceTolerance допустимое относительное отклонение дистанции выполненной тренировки от плановой.
+const AdherenceDistanceTolerance = 0.1
+
+// Adherence возвращает долю плановых тренировок, для которых есть выполненная тренировка
+// того же типа с дистанцией, отличающейся не более чем на AdherenceDistanceTolerance.
+// Каждая выполненная тренировка засчитывается только одной плановой.
+// Для пустого плана возвращает 0.
+func Adherence(planned, actual []CaloriesCalculator) float64 {
+	if len(planned) == 0 {
+		return 0
+	}
+	used := make([]bool, len(actual))
+	matched := 0
+	for _, plan := range planned {
+		planInfo := plan.TrainingInfo()
+		for i, done := range actual {
+			if used[i] {
+				continue
+			}
+			doneInfo := done.TrainingInfo()
+			if doneInfo.TrainingType == planInfo.TrainingType &&
+				math.Abs(doneInfo.Distance-planInfo.Distance) <= planInfo.Distance*AdherenceDistanceTolerance {
+				used[i] = true
+				matched++
+				break
+			}
+		}
+	}
+	return float64(matched) / float64(len(planned))
+}
+
+// TCXNamespace пространство имен формата Training Center XML (TCX).
+const TCXNamespace = "http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+
+// tcxDatabase корневой элемент документа TCX.
+type tcxDatabase struct {
+	XMLName    xml.Name      `xml:"TrainingCenterDatabase"`
+	Xmlns      string        `xml:"xmlns,attr"`
+	Activities []tcxActivity `xml:"Activities>Activity"`
+}
+
+// tcxActivity описывает тренировку в документе TCX.
+type tcxActivity struct {
+	Sport string `xml:"Sport,attr"`
+	ID    string `xml:"Id"`
+	Lap   tcxLap `xml:"Lap"`
+}
+
+// tcxLap описывает круг тренировки с обязательными по схеме TCX полями.
+type tcxLap struct {
+	StartTime        string  `xml:"StartTime,attr"`
+	TotalTimeSeconds float64 `xml:"TotalTimeSeconds"`
+	DistanceMeters   float64 `xml:"DistanceMeters"`
+	Calories         uint16  `xml:"Calories"`
+	Intensity        string  `xml:"Intensity"`
+	TriggerMethod    string  `xml:"TriggerMethod"`
+}
+
+// tcxSport возвращает вид спорта TCX для типа тренировки.
+// Схема TCX знает только Running, Biking и Other.
+func tcxSport(trainingType string) string {
+	if trainingType == RunningType {
+		return "Running"
+	}
+	return "Other"
+}
+
+// ExportTCX записывает в w тренировку в виде минимального документа TCX
+// с видом спорта, продолжительностью, дистанцией и калориями.
+// Тренировка без даты начала не экспортируется: TCX идентифицирует активность по времени старта.
+func ExportTCX(w io.Writer, t CaloriesCalculator) error {
+	if err := validateTraining(t); err != nil {
+		return fmt.Errorf("некорректная тренировка: %w", err)
+	}
+	date := dataOf(t).Date
+	if date.IsZero() {
+		return errors.New("некорректная тренировка: не указана дата начала")
+	}
+	info := t.TrainingInfo()
+	start := date.UTC().Format(time.RFC3339)
+	doc := tcxDatabase{
+		Xmlns: TCXNamespace,
+		Activities: []tcxActivity{{
+			Sport: tcxSport(info.TrainingType),
+			ID:    start,
+			Lap: tcxLap{
+				StartTime:        start,
+				TotalTimeSeconds: info.Duration.Seconds(),
+				DistanceMeters:   info.Distance * MInKm,
+				Calories:         uint16(math.Min(math.Round(info.Calories), math.MaxUint16)),
+				Intensity:        "Active",
+				TriggerMethod:    "Manual",
+			},
+		}},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("ошибка записи TCX: %w", err)
+	}
+	return nil
+}
+
+// FitnessTrendSlope возвращает наклон линейной регрессии ежедневного показателя по дням:
+// положительный при улучшении, отрицательный при спаде.
+// Если точек меньше двух, возвращает 0.
+// Формула расчета:
+// сумма((x - x_ср) * (y - y_ср)) / сумма((x - x_ср)^2), где x — номер дня
+func FitnessTrendSlope(dailyMetric []float64) float64 {
+	n := len(dailyMetric)
+	if n < 2 {
+		return 0
+	}
+	meanX := float64(n-1) / 2
+	var meanY float64
+	for _, y := range dailyMetric {
+		meanY += y
+	}
+	meanY /= float64(n)
+	var cov, varX float64
+	for i, y := range dailyMetric {
+		dx := float64(i) - meanX
+		cov += dx * (y - meanY)
+		varX += dx * dx
+	}
+	return cov / varX
+}
+
+// CaloriePercentByType возвращает долю каждого типа тренировки в суммарных килокалориях в процентах.
+// Если суммарное количество килокалорий равно 0, возвращает пустой результат.
+func CaloriePercentByType(trainings []CaloriesCalculator) map[string]float64 {
+	shares := make(map[string]float64)
+	total := totalCalories(trainings)
+	if total <= 0 {
+		return shares
+	}
+	for _, training := range trainings {
+		info := training.TrainingInfo()
+		shares[info.TrainingType] += info.Calories / total * 100
+	}
+	return shares
+}
+
+// DetectPlateau сообщает, что показатель вышел на плато: за последние window значений
+// лучший результат превысил значение в начале окна не более чем на tol.
+// Если значений меньше window или окно короче двух точек, возвращает false.
+func DetectPlateau(metric []float64, window int, tol float64) bool {
+	if window < 2 || len(metric) < window {
+		return false
+	}
+	recent := metric[len(metric)-window:]
+	best := recent[1]
+	for _, value := range recent[2:] {
+		best = math.Max(best, value)
+	}
+	return best-recent[0] <= tol
+}
+
+// Типичные параметры тренировок, по которым RecommendType сравнивает виды активности.
+const (
+	RecommendRunningCadence     = 160 // шагов в минуту при беге
+	RecommendWalkingCadence     = 100 // шагов в минуту при ходьбе
+	RecommendSwimmingStrokeRate = 30  // гребков в минуту при плавании
+	RecommendSwimmingSpeed      = 2.0 // скорость плавания в км/ч
+	RecommendPoolLength         = 25  // длина бассейна в м
+)
+
+// RecommendType возвращает тип тренировки, которая за время duration позволит потратить goalCalories килокалорий,
+// и саму тренировку с типичными параметрами для профиля.
+// Из подходящих тренировок выбирается наименее затратная, то есть достигающая цели с наименьшим запасом.
+// Если цели не достигает ни одна, выбирается тренировка с наибольшим расходом килокалорий.
+func RecommendType(goalCalories float64, duration time.Duration, profile Profile) (string, CaloriesCalculator) {
+	minutes := duration.Minutes()
+	candidates := []CaloriesCalculator{
+		profile.NewRunning(int(minutes*RecommendRunningCadence), duration),
+		profile.NewWalking(int(minutes*RecommendWalkingCadence), duration),
+		profile.NewSwimming(int(minutes*RecommendSwimmingStrokeRate), duration,
+			RecommendPoolLength, int(duration.Hours()*RecommendSwimmingSpeed*MInKm/RecommendPoolLength)),
+	}
+
+	var best CaloriesCalculator
+	bestReached := false
+	for _, candidate := range candidates {
+		calories := candidate.Calories()
+		reached := calories >= goalCalories
+		switch {
+		case best == nil,
+			reached && !bestReached,
+			reached && calories < best.Calories(),
+			!reached && !bestReached && calories > best.Calories():
+			best, bestReached = candidate, reached
+		}
+	}
+	return best.TrainingInfo().TrainingType, best
+}
+
+// Коды типов тренировок в сериализованном виде.
+const (
+	sessionKindRunning  = "running"
+	sessionKindWalking  = "walking"
+	sessionKindSwimming = "swimming"
+)
+
+// sessionRecord хранит тренировку вместе с ее типом, чтобы ее можно было восстановить из JSON.
+type sessionRecord struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// encodeSession возвращает сериализованную тренировку с указанием ее типа.
+func encodeSession(training CaloriesCalculator) (sessionRecord, error) {
+	var kind string
+	switch training.(type) {
+	case Running:
+		kind = sessionKindRunning
+	case Walking:
+		kind = sessionKindWalking
+	case Swimming:
+		kind = sessionKindSwimming
+	default:
+		return sessionRecord{}, fmt.Errorf("неизвестный тип тренировки %T", training)
+	}
+	data, err := json.Marshal(training)
+	if err != nil {
+		return sessionRecord{}, err
+	}
+	return sessionRecord{Kind: kind, Data: data}, nil
+}
+
+// decodeSession восстанавливает тренировку из сериализованного вида.
+func decodeSession(record sessionRecord) (CaloriesCalculator, error) {
+	switch record.Kind {
+	case sessionKindRunning:
+		var r Running
+		err := json.Unmarshal(record.Data, &r)
+		return r, err
+	case sessionKindWalking:
+		var w Walking
+		err := json.Unmarshal(record.Data, &w)
+		return w, err
+	case sessionKindSwimming:
+		var s Swimming
+		err := json.Unmarshal(record.Data, &s)
+		return s, err
+	}
+	return nil, fmt.Errorf("неизвестный тип тренировки %q", record.Kind)
+}
+
+// HistoryVersion текущая версия формата истории тренировок.
+const HistoryVersion = 1
+
+// history документ с историей тренировок.
+type history struct {
+	Version  int             `json:"version"`
+	Sessions []sessionRecord `json:"sessions"`
+}
+
+// MarshalHistory возвращает историю тренировок в виде JSON-документа с номером версии формата.
+func MarshalHistory(trainings []CaloriesCalculator) ([]byte, error) {
+	doc := history{Version: HistoryVersion, Sessions: make([]sessionRecord, 0, len(trainings))}
+	for i, training := range trainings {
+		record, err := encodeSession(training)
+		if err != nil {
+			return nil, fmt.Errorf("тренировка %d: %w", i, err)
+		}
+		doc.Sessions = append(doc.Sessions, record)
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalHistory восстанавливает историю тренировок из JSON-документа, полученного MarshalHistory.
+func UnmarshalHistory(data []byte) ([]CaloriesCalculator, error) {
+	var doc history
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("ошибка чтения истории: %w", err)
+	}
+	if doc.Version != HistoryVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия истории %d", doc.Version)
+	}
+	trainings := make([]CaloriesCalculator, 0, len(doc.Sessions))
+	for i, record := range doc.Sessions {
+		training, err := decodeSession(record)
+		if err != nil {
+			return nil, fmt.Errorf("тренировка %d: %w", i, err)
+		}
+		trainings = append(trainings, training)
+	}
+	return trainings, nil
+}
+
+// totalInfo возвращает суммарные длительность, дистанцию и килокалории по тренировкам
+// и общую среднюю скорость.
+func totalInfo(trainings []CaloriesCalculator) InfoMessage {
+	var total InfoMessage
+	for _, training := range trainings {
+		info := training.TrainingInfo()
+		total.Duration += info.Duration
+		total.Distance += info.Distance
+		total.Calories += info.Calories
+	}
+	if total.Duration > 0 {
+		total.Speed = total.Distance / total.Duration.Hours()
+	}
+	return total
+}
+
+// WeekOverWeek возвращает изменение суммарных показателей текущей недели относительно прошлой.
+// Если показатель прошлой недели равен 0, изменение в процентах для него равно 0.
+func WeekOverWeek(thisWeek, lastWeek []CaloriesCalculator) InfoDiff {
+	return diffInfo(totalInfo(thisWeek), totalInfo(lastWeek))
+}
+
+// ErrCorruptShortCode возвращается, если короткий код тренировки поврежден.
+var ErrCorruptShortCode = errors.New("поврежденный код тренировки")
+
+// shortCodeReader читает поля короткого кода, записанные shortCodeWriter.
+// При нехватке данных ok становится false, а поля читаются как нулевые.
+type shortCodeReader struct {
+	data []byte
+	ok   bool
+}
+
+// next возвращает следующие n байт.
+func (r *shortCodeReader) next(n int) []byte {
+	if !r.ok || len(r.data) < n {
+		r.ok = false
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+// uint32 читает целое поле.
+func (r *shortCodeReader) uint32() int {
+	return int(binary.BigEndian.Uint32(r.next(4)))
+}
+
+// float64 читает вещественное поле.
+func (r *shortCodeReader) float64() float64 {
+	return math.Float64frombits(binary.BigEndian.Uint64(r.next(8)))
+}
+
+// seconds читает длительность, записанную в целых секундах.
+func (r *shortCodeReader) seconds() time.Duration {
+	return time.Duration(r.uint32()) * time.Second
+}
+
+// FromShortCode восстанавливает тренировку из короткого кода, полученного ToShortCode.
+// Дата восстанавливается с точностью до секунды в UTC.
+func FromShortCode(code string) (CaloriesCalculator, error) {
+	data, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil || len(data) < 2+4 {
+		return nil, ErrCorruptShortCode
+	}
+	payload, sum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(payload) != sum {
+		return nil, ErrCorruptShortCode
+	}
+	if payload[0] != ShortCodeVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия кода тренировки %d", payload[0])
+	}
+
+	kind := payload[1]
+	r := shortCodeReader{data: payload[2:], ok: true}
+	t := Training{
+		Action:   r.uint32(),
+		LenStep:  r.float64(),
+		Duration: r.seconds(),
+		Weight:   r.float64(),
+	}
+	date := int64(binary.BigEndian.Uint64(r.next(8)))
+	t.AvgHeartRate = r.uint32()
+	t.DaysOff = r.uint32()
+	flags := r.next(1)[0]
+	t.FatigueModel = flags&shortCodeFatigue != 0
+	if flags&shortCodeDated != 0 {
+		t.Date = time.Unix(date, 0).UTC()
+	}
+	count := r.uint32()
+	if count > len(r.data)/4 {
+		return nil, ErrCorruptShortCode
+	}
+	for i := 0; i < count; i++ {
+		t.Pauses = append(t.Pauses, r.seconds())
+	}
+
+	var training CaloriesCalculator
+	switch kind {
+	case shortCodeRunning:
+		t.TrainingType = RunningType
+		training = Running{Training: t, ElevationGain: r.float64()}
+	case shortCodeWalking:
+		t.TrainingType = WalkingType
+		training = Walking{Training: t, Height: r.float64(), ElevationGain: r.float64()}
+	case shortCodeSwimming:
+		t.TrainingType = SwimmingType
+		training = Swimming{
+			Training:         t,
+			LengthPool:       r.uint32(),
+			CountPool:        r.uint32(),
+			StrokesPerLength: r.uint32(),
+			PartialLength:    r.float64(),
+		}
+	}
+	if training == nil || !r.ok || len(r.data) != 0 {
+		return nil, ErrCorruptShortCode
+	}
+	return training, nil
+}
+
+// burnRate возвращает средний расход килокалорий в минуту движения.
+func burnRate(training CaloriesCalculator) float64 {
+	moving := movingTimeOf(training)
+	if moving <= 0 {
+		return 0
+	}
+	return training.Calories() / moving.Minutes()
+}
+
+// BreakEvenDuration возвращает, сколько нужно заниматься активностью b в ее текущем темпе,
+// чтобы потратить столько же килокалорий, сколько на тренировке a.
+// Если расход килокалорий у одной из тренировок не положительный, возвращает 0.
+func BreakEvenDuration(a, b CaloriesCalculator) time.Duration {
+	rateA, rateB := burnRate(a), burnRate(b)
+	if rateA <= 0 || rateB <= 0 {
+		return 0
+	}
+	return time.Duration(a.Calories() / rateB * float64(time.Minute))
+}
+
+// MilestoneCrossed возвращает отметки суммарной дистанции в км, которые были пройдены благодаря тренировке session,
+// если до нее суммарная дистанция составляла cumulativeBefore км.
+func MilestoneCrossed(cumulativeBefore float64, session CaloriesCalculator, milestones []float64) []float64 {
+	after := cumulativeBefore + session.TrainingInfo().Distance
+	var crossed []float64
+	for _, milestone := range milestones {
+		if cumulativeBefore < milestone && milestone <= after {
+			crossed = append(crossed, milestone)
+		}
+	}
+	return crossed
+}
+
+// ReadinessMinFactor доля дневной цели, сохраняемая при минимальной готовности к нагрузке.
+const ReadinessMinFactor = 0.5
+
+// AdjustedTarget возвращает дневную цель по килокалориям с учетом готовности к нагрузке readiness от 0 до 1.
+// Готовность ограничивается этим диапазоном.
+// Формула расчета:
+// цель * (0.5 + 0.5 * готовность)
+func AdjustedTarget(baseTarget, readiness float64) float64 {
+	readiness = math.Max(0, math.Min(1, readiness))
+	return baseTarget * (ReadinessMinFactor + (1-ReadinessMinFactor)*readiness)
+}
+
+// ForecastDaysInMonth количество дней в месяце для прогноза.
+const ForecastDaysInMonth = 30
+
+// ForecastMonthlyCalories возвращает прогноз килокалорий за месяц
+// по среднему дневному расходу на тренировках за последние days дней.
+// При days <= 0 возвращает 0.
+func ForecastMonthlyCalories(recent []CaloriesCalculator, days int) float64 {
+	if days <= 0 {
+		return 0
+	}
+	return totalCalories(recent) / float64(days) * ForecastDaysInMonth
+}
+
+// metrics содержит все показатели тренировки для сравнения.
+var metrics = []Metric{MetricDistance, MetricDuration, MetricSpeed, MetricCalories}
+
+// MostImproved возвращает показатель, который сильнее всего вырос за историю тренировок, и его рост в процентах.
+// Сравниваются средние значения первой и последней четверти истории, упорядоченной по времени.
+// Если тренировок меньше двух, возвращает MetricDistance и 0.
+func MostImproved(history []CaloriesCalculator) (Metric, float64) {
+	if len(history) < 2 {
+		return MetricDistance, 0
+	}
+	quarter := len(history) / 4
+	if quarter == 0 {
+		quarter = 1
+	}
+	first, last := history[:quarter], history[len(history)-quarter:]
+	mean := func(trainings []CaloriesCalculator, metric Metric) float64 {
+		var sum float64
+		for _, training := range trainings {
+			sum += metric.value(training.TrainingInfo())
+		}
+		return sum / float64(len(trainings))
+	}
+
+	best, bestChange := metrics[0], math.Inf(-1)
+	for _, metric := range metrics {
+		change := percentChange(mean(last, metric), mean(first, metric))
+		if change > bestChange {
+			best, bestChange = metric, change
+		}
+	}
+	return best, bestChange
+}
+
+// Константы для расчета целевого пульса.
+const (
+	MaxHeartRateBase     = 220  // из этого значения вычитается возраст для оценки максимального пульса
+	TargetHRLowFraction  = 0.5  // нижняя граница тренировочного диапазона от максимального пульса
+	TargetHRHighFraction = 0.85 // верхняя граница тренировочного диапазона от максимального пульса
+)
+
+// TargetHRRange возвращает тренировочный диапазон пульса (50–85% от максимального) для возраста age.
+// Максимальный пульс оценивается как 220 - возраст. Для некорректного возраста возвращает нули.
+func TargetHRRange(age int) (low, high int) {
+	if age <= 0 || age >= MaxHeartRateBase {
+		return 0, 0
+	}
+	maxHR := float64(MaxHeartRateBase - age)
+	return int(math.Round(maxHR * TargetHRLowFraction)), int(math.Round(maxHR * TargetHRHighFraction))
+}
+
+// ConsistencyScore возвращает долю дней с тренировками за последние periodDays дней, включая сегодняшний,
+// в виде оценки от 0 до 1. При periodDays <= 0 возвращает 0.
+// Дни определяются в часовом поясе пользователя loc; при loc = nil используется UTC.
+func ConsistencyScore(dates []time.Time, periodDays int, loc *time.Location) float64 {
+	return consistencyScore(dates, periodDays, time.Now(), loc)
+}
+
+// consistencyScore возвращает долю дней с тренировками за periodDays дней,
+// заканчивающихся днем now в часовом поясе loc.
+func consistencyScore(dates []time.Time, periodDays int, now time.Time, loc *time.Location) float64 {
+	if periodDays <= 0 {
+		return 0
+	}
+	loc = locationOrUTC(loc)
+	now = now.In(loc)
+	last := dayStart(now)
+	first := last.AddDate(0, 0, -(periodDays - 1))
+	active := make(map[time.Time]bool)
+	for _, date := range dates {
+		day := dayStart(date.In(loc))
+		if !day.Before(first) && !day.After(last) {
+			active[day] = true
+		}
+	}
+	return float64(len(active)) / float64(periodDays)
+}
+
+// SameDayStrainFactor надбавка к суммарной нагрузке за каждую дополнительную тренировку в тот же день.
+const SameDayStrainFactor = 0.1
+
+// CombinedStrain возвращает суммарную нагрузку нескольких тренировок в MET-минутах.
+// Если тренировки проведены в один день (sameDay), нагрузка увеличивается на SameDayStrainFactor
+// за каждую тренировку после первой, отражая накопленную усталость.
+// Формула расчета:
+// сумма(нагрузка) * (1 + 0.1 * (количество_тренировок - 1)) для одного дня,
+// сумма(нагрузка) для разных дней
+func CombinedStrain(sessions []CaloriesCalculator, sameDay bool) float64 {
+	var strain float64
+	for _, session := range sessions {
+		strain += EffortScore(session)
+	}
+	if sameDay && len(sessions) > 1 {
+		strain *= 1 + SameDayStrainFactor*float64(len(sessions)-1)
+	}
+	return strain
 }
 
 // ReadData возвращает информацию о проведенной тренировке.
 func ReadData(training CaloriesCalculator) string {
 	// получите количество затраченных калорий
-	calories := ...
+	calories := training.Calories()
 
 	// получите информацию о тренировке
-	info := ...
+	info := training.TrainingInfo()
 	// добавьте полученные калории в структуру с информацией о тренировке
-	...
+	info.Calories = calories
 
 	return fmt.Sprint(info)
 }
@@ -0,0 +1,1937 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+// eps допустимая погрешность при сравнении дробных значений.
+const eps = 1e-6
+
+// almostEqual сообщает, совпадают ли значения с точностью до eps.
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < eps
+}
+
+// newRunning возвращает тренировку Бег пользователя весом 85 кг.
+func newRunning(action int, lenStep float64, d time.Duration) Running {
+	return Running{
+		Training: Training{TrainingType: "Бег", Action: action, LenStep: lenStep, Duration: d, Weight: 85},
+	}
+}
+
+// newWalking возвращает тренировку Ходьба пользователя весом 85 кг и ростом 185 см.
+func newWalking(action int, lenStep float64, d time.Duration) Walking {
+	return Walking{
+		Training: Training{TrainingType: "Ходьба", Action: action, LenStep: lenStep, Duration: d, Weight: 85},
+		Height:   185,
+	}
+}
+
+// newSwimming возвращает тренировку Плавание пользователя весом 85 кг в 50-метровом бассейне.
+func newSwimming(countPool int, d time.Duration) Swimming {
+	return Swimming{
+		Training:   Training{TrainingType: "Плавание", Action: 2000, LenStep: SwimmingLenStep, Duration: d, Weight: 85},
+		LengthPool: 50,
+		CountPool:  countPool,
+	}
+}
+
+// Тренировки из main, для которых результаты известны.
+var (
+	testRunning  = newRunning(5000, LenStep, 30*time.Minute)
+	testWalking  = newWalking(20000, LenStep, 3*time.Hour+45*time.Minute)
+	testSwimming = newSwimming(5, 90*time.Minute)
+)
+
+func TestCalories(t *testing.T) {
+	tests := []struct {
+		name     string
+		training CaloriesCalculator
+		want     float64
+	}{
+		{"running", testRunning, 302.9145},
+		{"walking", testWalking, 947.8213},
+		{"swimming", testSwimming, 323},
+	}
+	for _, tt := range tests {
+		if got := tt.training.Calories(); math.Abs(got-tt.want) > 1e-3 {
+			t.Errorf("%s: Calories() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadData(t *testing.T) {
+	want := "Тип тренировки: Бег\nДлительность: 30 мин\nДистанция: 3.25 км.\nСр. скорость: 6.50 км/ч\nПотрачено ккал: 302.91\n"
+	if got := ReadData(testRunning); got != want {
+		t.Errorf("ReadData() = %q, want %q", got, want)
+	}
+}
+
+func TestTimeToBurn(t *testing.T) {
+	calories := testRunning.Calories()
+	rate := calories / 30
+
+	if got := TimeToBurn(testRunning, calories+rate*15); (got - 15*time.Minute).Abs() > time.Second {
+		t.Errorf("TimeToBurn() for partial goal = %v, want 15m", got)
+	}
+	if got := TimeToBurn(testRunning, calories-1); got != 0 {
+		t.Errorf("TimeToBurn() for reached goal = %v, want 0", got)
+	}
+	idle := newRunning(0, LenStep, 0)
+	if got := TimeToBurn(idle, 100); got != 0 {
+		t.Errorf("TimeToBurn() for zero burn rate = %v, want 0", got)
+	}
+}
+
+// customTraining тренировка, реализующая только CaloriesCalculator.
+type customTraining struct{}
+
+func (customTraining) Calories() float64 { return 120 }
+
+func (customTraining) TrainingInfo() InfoMessage {
+	return InfoMessage{TrainingType: "Йога", Duration: time.Hour, Calories: 120}
+}
+
+func TestDerivedMetricsForCustomTraining(t *testing.T) {
+	var c CaloriesCalculator = customTraining{}
+	if got := IntensityCategory(c); got != IntensityLight {
+		t.Errorf("IntensityCategory() = %q, want %q", got, IntensityLight)
+	}
+	if got := IntensityProxy(c); got != 0 {
+		t.Errorf("IntensityProxy() = %v, want 0", got)
+	}
+	if got := EffortScore(c); got != 0 {
+		t.Errorf("EffortScore() without weight = %v, want 0", got)
+	}
+	if got := ToActivity(c); got.Name != "Йога" || got.Type != "" || got.Calories != 120 {
+		t.Errorf("ToActivity() = %+v, want name Йога without type", got)
+	}
+	if got := AtWeight(c, 70); got != c {
+		t.Errorf("AtWeight() = %v, want the training unchanged", got)
+	}
+	if got := TimeToBurn(c, 180); got != 30*time.Minute {
+		t.Errorf("TimeToBurn() = %v, want 30m", got)
+	}
+}
+
+func TestFormatWith(t *testing.T) {
+	tmpl := template.Must(template.New("custom").Parse(`{{.TrainingType}}: {{printf "%.2f" .Distance}} км`))
+	got, err := testRunning.TrainingInfo().FormatWith(tmpl)
+	if err != nil {
+		t.Fatalf("FormatWith() error = %v", err)
+	}
+	if want := "Бег: 3.25 км"; got != want {
+		t.Errorf("FormatWith() = %q, want %q", got, want)
+	}
+
+	broken := template.Must(template.New("broken").Parse(`{{.Missing}}`))
+	if _, err := testRunning.TrainingInfo().FormatWith(broken); err == nil {
+		t.Error("FormatWith() with unknown field: expected error")
+	}
+	if _, err := testRunning.TrainingInfo().FormatWith(nil); err == nil {
+		t.Error("FormatWith(nil): expected error")
+	}
+}
+
+func TestSWOLF(t *testing.T) {
+	// 20 длин за 10 минут — по 30 секунд на длину, плюс 15 гребков.
+	s := newSwimming(20, 10*time.Minute)
+	s.StrokesPerLength = 15
+	if got := s.SWOLF(); !almostEqual(got, 45) {
+		t.Errorf("SWOLF() = %v, want 45", got)
+	}
+
+	// 12.5 длины за те же 10 минут — по 48 секунд на длину.
+	s = newSwimming(12, 10*time.Minute)
+	s.StrokesPerLength = 15
+	s.PartialLength = 0.5
+	if got := s.SWOLF(); !almostEqual(got, 63) {
+		t.Errorf("SWOLF() with partial length = %v, want 63", got)
+	}
+
+	s.CountPool, s.PartialLength = 0, 0
+	if got := s.SWOLF(); got != 0 {
+		t.Errorf("SWOLF() with zero count = %v, want 0", got)
+	}
+}
+
+func TestAcuteChronicRatio(t *testing.T) {
+	tests := []struct {
+		thisWeek, avg float64
+		ratio         float64
+		risk          bool
+	}{
+		{1200, 1000, 1.2, false},
+		{1500, 1000, 1.5, false},
+		{1800, 1000, 1.8, true},
+		{1000, 0, 0, false},
+	}
+	for _, tt := range tests {
+		if got := AcuteChronicRatio(tt.thisWeek, tt.avg); !almostEqual(got, tt.ratio) {
+			t.Errorf("AcuteChronicRatio(%v, %v) = %v, want %v", tt.thisWeek, tt.avg, got, tt.ratio)
+		}
+		if got := IsOvertrainingRisk(tt.thisWeek, tt.avg); got != tt.risk {
+			t.Errorf("IsOvertrainingRisk(%v, %v) = %v, want %v", tt.thisWeek, tt.avg, got, tt.risk)
+		}
+	}
+}
+
+func TestSwimmingPartialLength(t *testing.T) {
+	s := newSwimming(10, time.Hour)
+	if got := s.meanSpeed(); !almostEqual(got, 0.5) {
+		t.Errorf("meanSpeed() without partial length = %v, want 0.5", got)
+	}
+
+	s.PartialLength = 0.5
+	if got := s.poolDistance(); !almostEqual(got, 525) {
+		t.Errorf("poolDistance() = %v, want 525", got)
+	}
+	if got := s.meanSpeed(); !almostEqual(got, 0.525) {
+		t.Errorf("meanSpeed() = %v, want 0.525", got)
+	}
+}
+
+func TestVsAverage(t *testing.T) {
+	history := []CaloriesCalculator{
+		newRunning(4000, LenStep, 30*time.Minute),
+		newRunning(4000, LenStep, 30*time.Minute),
+	}
+	diff := VsAverage(testRunning, history)
+	if !almostEqual(diff.Distance, 0.65) {
+		t.Errorf("Distance = %v, want 0.65", diff.Distance)
+	}
+	if !almostEqual(diff.SpeedPercent, 25) {
+		t.Errorf("SpeedPercent = %v, want 25", diff.SpeedPercent)
+	}
+	if diff.Duration != 0 || diff.DurationPercent != 0 {
+		t.Errorf("Duration diff = %v (%v%%), want 0", diff.Duration, diff.DurationPercent)
+	}
+
+	if got := VsAverage(testRunning, nil); got != (InfoDiff{}) {
+		t.Errorf("VsAverage(nil) = %+v, want zero diff", got)
+	}
+}
+
+func TestRecommendedWaterML(t *testing.T) {
+	// 90 минут плавания и 323 ккал: 400 * 1.5 + 0.5 * 323.
+	got := RecommendedWaterML(testSwimming)
+	if !almostEqual(got, 761.5) {
+		t.Errorf("RecommendedWaterML() = %v, want 761.5", got)
+	}
+	if got < 500 || got > 1500 {
+		t.Errorf("RecommendedWaterML() = %v, outside plausible range", got)
+	}
+}
+
+func TestPlanDelta(t *testing.T) {
+	a := []CaloriesCalculator{testRunning, testRunning}
+	b := []CaloriesCalculator{testWalking}
+	want := 2*testRunning.Calories() - testWalking.Calories()
+	if got := PlanDelta(a, b); !almostEqual(got, want) {
+		t.Errorf("PlanDelta() = %v, want %v", got, want)
+	}
+	if got := PlanDelta(b, a); !almostEqual(got, -want) {
+		t.Errorf("PlanDelta() reversed = %v, want %v", got, -want)
+	}
+	if got := PlanDelta(nil, nil); got != 0 {
+		t.Errorf("PlanDelta(nil, nil) = %v, want 0", got)
+	}
+}
+
+func TestDistanceFromSteps(t *testing.T) {
+	if got := DistanceFromSteps(5000, 0.65); !almostEqual(got, 3.25) {
+		t.Errorf("DistanceFromSteps(5000, 0.65) = %v, want 3.25", got)
+	}
+	if got := DistanceFromSteps(-1, 0.65); got != 0 {
+		t.Errorf("DistanceFromSteps(-1, 0.65) = %v, want 0", got)
+	}
+}
+
+func TestInfoMessageNote(t *testing.T) {
+	info := testRunning.TrainingInfo()
+	plain := info.String()
+	if strings.Contains(plain, "Заметка") {
+		t.Errorf("String() without note contains a note: %q", plain)
+	}
+
+	info.Note = "felt great"
+	if got, want := info.String(), plain+"Заметка: felt great\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestEPOCCalories(t *testing.T) {
+	calories := testRunning.Calories()
+	// 30 минут бега: около 10.1 ккал в минуту, интенсивность 0.67 от максимальной.
+	intensity := calories / 30 / EPOCMaxCaloriesPerMinute
+	want := calories * (EPOCMinFraction + (EPOCMaxFraction-EPOCMinFraction)*intensity)
+	if got := EPOCCalories(testRunning); !almostEqual(got, want) {
+		t.Errorf("EPOCCalories() = %v, want %v", got, want)
+	}
+	if got := EPOCCalories(testRunning); got < calories*EPOCMinFraction || got > calories*EPOCMaxFraction {
+		t.Errorf("EPOCCalories() = %v, outside [%v, %v]", got, calories*EPOCMinFraction, calories*EPOCMaxFraction)
+	}
+	if got := TotalWithEPOC(testRunning); !almostEqual(got, calories+want) {
+		t.Errorf("TotalWithEPOC() = %v, want %v", got, calories+want)
+	}
+
+	idle := newRunning(0, LenStep, 0)
+	if got := EPOCCalories(idle); got != 0 {
+		t.Errorf("EPOCCalories() for empty training = %v, want 0", got)
+	}
+}
+
+func TestIntensityCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		training CaloriesCalculator
+		want     string
+	}{
+		{"running 8 km/h", newRunning(8000, 1, time.Hour), IntensityVigorous},
+		{"running 7.999 km/h", newRunning(7999, 1, time.Hour), IntensityModerate},
+		{"running 5 km/h", newRunning(5000, 1, time.Hour), IntensityModerate},
+		{"running 4.999 km/h", newRunning(4999, 1, time.Hour), IntensityLight},
+		{"walking 6.5 km/h", newWalking(6500, 1, time.Hour), IntensityVigorous},
+		{"walking 4 km/h", newWalking(4000, 1, time.Hour), IntensityModerate},
+		{"walking 3.999 km/h", newWalking(3999, 1, time.Hour), IntensityLight},
+		{"swimming 2.5 km/h", newSwimming(50, time.Hour), IntensityVigorous},
+		{"swimming 1.5 km/h", newSwimming(30, time.Hour), IntensityModerate},
+		{"swimming 1.45 km/h", newSwimming(29, time.Hour), IntensityLight},
+	}
+	for _, tt := range tests {
+		if got := IntensityCategory(tt.training); got != tt.want {
+			t.Errorf("%s: IntensityCategory() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCountByIntensity(t *testing.T) {
+	trainings := []CaloriesCalculator{
+		newRunning(10000, 1, time.Hour),
+		newWalking(3000, 1, time.Hour),
+		newSwimming(30, time.Hour),
+		newSwimming(40, time.Hour),
+	}
+	got := CountByIntensity(trainings)
+	if got[IntensityVigorous] != 1 || got[IntensityModerate] != 2 || got[IntensityLight] != 1 {
+		t.Errorf("CountByIntensity() = %v, want 1 vigorous, 2 moderate, 1 light", got)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	p := Profile{Weight: 85, Height: 185}
+
+	r := p.NewRunning(5000, 30*time.Minute)
+	if r.TrainingType != RunningType || r.LenStep != LenStep || r.Weight != 85 {
+		t.Errorf("NewRunning() = %+v, want running with default step and profile weight", r)
+	}
+	if got, want := r.Calories(), testRunning.Calories(); !almostEqual(got, want) {
+		t.Errorf("NewRunning().Calories() = %v, want %v", got, want)
+	}
+
+	w := p.NewWalking(20000, 3*time.Hour+45*time.Minute)
+	if w.TrainingType != WalkingType || w.Height != 185 {
+		t.Errorf("NewWalking() = %+v, want walking with profile height", w)
+	}
+	if got, want := w.Calories(), testWalking.Calories(); !almostEqual(got, want) {
+		t.Errorf("NewWalking().Calories() = %v, want %v", got, want)
+	}
+
+	s := p.NewSwimming(2000, 90*time.Minute, 50, 5)
+	if s.TrainingType != SwimmingType || s.LenStep != SwimmingLenStep || s.LengthPool != 50 || s.CountPool != 5 {
+		t.Errorf("NewSwimming() = %+v, want swimming with pool parameters", s)
+	}
+	if got, want := s.Calories(), testSwimming.Calories(); !almostEqual(got, want) {
+		t.Errorf("NewSwimming().Calories() = %v, want %v", got, want)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	near := newRunning(5000, LenStep, 30*time.Minute+30*time.Second)
+	sameLength := newWalking(5000, LenStep, 30*time.Minute)
+	trainings := []CaloriesCalculator{testRunning, testRunning, near, sameLength}
+
+	if got := Dedup(trainings, time.Minute); len(got) != 2 {
+		t.Errorf("Dedup() with 1m tolerance kept %d trainings, want 2", len(got))
+	}
+	if got := Dedup(trainings, 10*time.Second); len(got) != 3 {
+		t.Errorf("Dedup() with 10s tolerance kept %d trainings, want 3", len(got))
+	}
+	if !IsDuplicate(testRunning, near, time.Minute) {
+		t.Error("IsDuplicate() = false for sessions 30s apart, want true")
+	}
+	if IsDuplicate(testRunning, sameLength, time.Minute) {
+		t.Error("IsDuplicate() = true for different training types, want false")
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 18, 0, 0, 0, time.UTC)
+	day := func(offset int) time.Time { return now.AddDate(0, 0, -offset).Add(-time.Hour) }
+
+	tests := []struct {
+		name  string
+		dates []time.Time
+		want  int
+	}{
+		{"five days", []time.Time{day(0), day(1), day(2), day(3), day(4)}, 5},
+		{"broken", []time.Time{day(0), day(1), day(3), day(4)}, 2},
+		{"from yesterday", []time.Time{day(1), day(2)}, 2},
+		{"two days ago", []time.Time{day(2)}, 0},
+		{"empty", nil, 0},
+	}
+	for _, tt := range tests {
+		if got := currentStreak(tt.dates, now, nil); got != tt.want {
+			t.Errorf("%s: currentStreak() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// Тренировка в 22:30 UTC 8 марта — это уже 9 марта в Москве (UTC+3).
+	moscow := time.FixedZone("MSK", 3*60*60)
+	late := []time.Time{time.Date(2024, time.March, 8, 22, 30, 0, 0, time.UTC), day(0)}
+	if got := currentStreak(late, now, moscow); got != 2 {
+		t.Errorf("currentStreak() in UTC+3 = %d, want 2", got)
+	}
+	if got := currentStreak(late, now, nil); got != 1 {
+		t.Errorf("currentStreak() in UTC = %d, want 1", got)
+	}
+}
+
+func TestEffortScore(t *testing.T) {
+	run, walk := EffortScore(testRunning), EffortScore(testWalking)
+	// Короткий тяжелый бег интенсивнее за минуту, но долгая ходьба дает большую суммарную нагрузку.
+	if run/30 <= walk/225 {
+		t.Errorf("running MET %v should exceed walking MET %v", run/30, walk/225)
+	}
+	if walk <= run {
+		t.Errorf("EffortScore() walking %v should exceed running %v", walk, run)
+	}
+	if want := 30 * testRunning.Calories() / 85 / 0.5; !almostEqual(run, want) {
+		t.Errorf("EffortScore() = %v, want %v", run, want)
+	}
+}
+
+func TestToActivity(t *testing.T) {
+	tests := []struct {
+		training CaloriesCalculator
+		name     string
+		kind     string
+		distance float64
+	}{
+		{testRunning, "Бег", ActivityRun, 3250},
+		{testWalking, "Ходьба", ActivityWalk, 13000},
+		{testSwimming, "Плавание", ActivitySwim, 2760},
+	}
+	for _, tt := range tests {
+		got := ToActivity(tt.training)
+		if got.Name != tt.name || got.Type != tt.kind || !almostEqual(got.Distance, tt.distance) {
+			t.Errorf("ToActivity() = %+v, want name %q, type %q, distance %v", got, tt.name, tt.kind, tt.distance)
+		}
+		if !almostEqual(got.Calories, tt.training.Calories()) {
+			t.Errorf("ToActivity().Calories = %v, want %v", got.Calories, tt.training.Calories())
+		}
+	}
+	if got := ToActivity(testRunning).ElapsedTime; got != 1800 {
+		t.Errorf("ElapsedTime = %d, want 1800", got)
+	}
+}
+
+func TestPredictTime(t *testing.T) {
+	r := newRunning(5000, 1, 25*time.Minute)
+	// 25 мин * 2^1.06 ≈ 52 мин 7 с.
+	got := r.PredictTime(10)
+	if got < 52*time.Minute || got > 52*time.Minute+10*time.Second {
+		t.Errorf("PredictTime(10) = %v, want about 52m7s", got)
+	}
+	if got := r.PredictTime(5); got != 25*time.Minute {
+		t.Errorf("PredictTime(5) = %v, want 25m", got)
+	}
+	if got := r.PredictTime(-1); got != 0 {
+		t.Errorf("PredictTime(-1) = %v, want 0", got)
+	}
+}
+
+func TestCaloriesPerKm(t *testing.T) {
+	if got, want := testRunning.CaloriesPerKm(), testRunning.Calories()/3.25; !almostEqual(got, want) {
+		t.Errorf("Running.CaloriesPerKm() = %v, want %v", got, want)
+	}
+	// Для плавания дистанция берется по бассейну: 5 * 50 м = 0.25 км.
+	if got, want := CaloriesPerKm(testSwimming), testSwimming.Calories()/0.25; !almostEqual(got, want) {
+		t.Errorf("CaloriesPerKm(swimming) = %v, want %v", got, want)
+	}
+	if got := CaloriesPerKm(newRunning(0, LenStep, time.Hour)); got != 0 {
+		t.Errorf("CaloriesPerKm() for zero distance = %v, want 0", got)
+	}
+}
+
+func TestTimeWeightedMeanSpeed(t *testing.T) {
+	trainings := []CaloriesCalculator{
+		newRunning(10000, 1, time.Hour),    // 10 км/ч
+		newRunning(2000, 1, 6*time.Minute), // 20 км/ч
+	}
+	// (10 * 1 + 20 * 0.1) / 1.1, а не простое среднее 15.
+	if got, want := TimeWeightedMeanSpeed(trainings), 12/1.1; !almostEqual(got, want) {
+		t.Errorf("TimeWeightedMeanSpeed() = %v, want %v", got, want)
+	}
+	if got := TimeWeightedMeanSpeed(nil); got != 0 {
+		t.Errorf("TimeWeightedMeanSpeed(nil) = %v, want 0", got)
+	}
+}
+
+func TestCaloriesAtAltitude(t *testing.T) {
+	calories := testRunning.Calories()
+	if got := CaloriesAtAltitude(testRunning, 0); !almostEqual(got, calories) {
+		t.Errorf("CaloriesAtAltitude(0) = %v, want %v", got, calories)
+	}
+	if got, want := CaloriesAtAltitude(testRunning, 2500), calories*1.05; !almostEqual(got, want) {
+		t.Errorf("CaloriesAtAltitude(2500) = %v, want %v", got, want)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	untyped := newWalking(1000, LenStep, time.Hour)
+	untyped.TrainingType = ""
+	negativePause := newRunning(5000, LenStep, 30*time.Minute)
+	negativePause.Pauses = []time.Duration{-time.Hour}
+	trainings := []CaloriesCalculator{testRunning, nil, newRunning(5000, LenStep, 0), untyped, negativePause}
+
+	valid, messages := Sanitize(trainings)
+	if len(valid) != 1 {
+		t.Errorf("Sanitize() kept %d trainings, want 1", len(valid))
+	}
+	if len(messages) != 4 {
+		t.Errorf("Sanitize() returned %d messages, want 4: %v", len(messages), messages)
+	}
+}
+
+func TestTRIMP(t *testing.T) {
+	r := newRunning(5000, LenStep, 30*time.Minute)
+	r.AvgHeartRate = 130
+	// Резерв пульса (130 - 40) / (200 - 40) = 0.5625.
+	// Мужчины: 30 * 0.5625 * 0.64 * e^(1.92 * 0.5625) ≈ 31.80.
+	if got := r.TRIMP(200, 40, true); math.Abs(got-31.80) > 0.01 {
+		t.Errorf("TRIMP() male = %v, want 31.80", got)
+	}
+	// Женщины: 30 * 0.5625 * 0.86 * e^(1.67 * 0.5625) ≈ 37.13.
+	if got := r.TRIMP(200, 40, false); math.Abs(got-37.13) > 0.01 {
+		t.Errorf("TRIMP() female = %v, want 37.13", got)
+	}
+	if got := testRunning.TRIMP(200, 40, true); got != 0 {
+		t.Errorf("TRIMP() without heart rate = %v, want 0", got)
+	}
+}
+
+func TestVsPacer(t *testing.T) {
+	// 3.25 км за 30 минут.
+	if got, want := testRunning.VsPacer(10), 2*time.Minute+30*time.Second; (got - want).Abs() > time.Millisecond {
+		t.Errorf("VsPacer(10) = %v, want %v ahead", got, want)
+	}
+	if got, want := testRunning.VsPacer(8), -4*time.Minute; (got - want).Abs() > time.Millisecond {
+		t.Errorf("VsPacer(8) = %v, want %v behind", got, want)
+	}
+	if got := testRunning.VsPacer(0); got != 0 {
+		t.Errorf("VsPacer(0) = %v, want 0", got)
+	}
+}
+
+func TestInfoMessageBinaryRoundTrip(t *testing.T) {
+	want := testWalking.TrainingInfo()
+	want.Note = "по набережной"
+	data, err := want.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	var got InfoMessage
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+
+	if err := got.UnmarshalBinary(data[:len(data)-3]); err == nil {
+		t.Error("UnmarshalBinary() with truncated data: expected error")
+	}
+	if err := got.UnmarshalBinary(append(data, 0)); err == nil {
+		t.Error("UnmarshalBinary() with trailing data: expected error")
+	}
+}
+
+func TestFatigueModel(t *testing.T) {
+	short := newRunning(10000, 1, time.Hour)
+	base := short.Calories()
+	short.FatigueModel = true
+	if got := short.Calories(); !almostEqual(got, base) {
+		t.Errorf("Calories() for 60 min with fatigue = %v, want unchanged %v", got, base)
+	}
+
+	long := newRunning(30000, 1, 3*time.Hour)
+	base = long.Calories()
+	long.FatigueModel = true
+	// Первые 90 минут без изменений, следующие 90 минут — 0.9 от обычного расхода.
+	if got, want := long.Calories(), base*0.95; !almostEqual(got, want) {
+		t.Errorf("Calories() for 180 min with fatigue = %v, want %v", got, want)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		if got := median(tt.values); got != tt.want {
+			t.Errorf("median(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+
+	trainings := []CaloriesCalculator{
+		newRunning(5000, 1, time.Hour),
+		newRunning(50000, 1, time.Hour), // выброс не влияет на медиану
+		newRunning(7000, 1, time.Hour),
+	}
+	if got := MedianDistance(trainings); got != 7 {
+		t.Errorf("MedianDistance() = %v, want 7", got)
+	}
+	if got := MedianSpeed(trainings); got != 7 {
+		t.Errorf("MedianSpeed() = %v, want 7", got)
+	}
+	if got, want := MedianCalories(trainings), trainings[2].Calories(); got != want {
+		t.Errorf("MedianCalories() = %v, want %v", got, want)
+	}
+}
+
+func TestTotalZoneMinutes(t *testing.T) {
+	r, s := testRunning, testSwimming
+	r.HRZoneMinutes = map[int]float64{1: 5, 2: 20, 3: 5}
+	s.HRZoneMinutes = map[int]float64{2: 60, 4: 10}
+
+	got := TotalZoneMinutes([]CaloriesCalculator{r, s, testWalking})
+	want := map[int]float64{1: 5, 2: 80, 3: 5, 4: 10}
+	if len(got) != len(want) {
+		t.Fatalf("TotalZoneMinutes() = %v, want %v", got, want)
+	}
+	for zone, minutes := range want {
+		if got[zone] != minutes {
+			t.Errorf("zone %d = %v, want %v", zone, got[zone], minutes)
+		}
+	}
+}
+
+func TestCalibrateStepLength(t *testing.T) {
+	if got := CalibrateStepLength(5, 6500); !almostEqual(got, 5000.0/6500) {
+		t.Errorf("CalibrateStepLength(5, 6500) = %v, want %v", got, 5000.0/6500)
+	}
+	if got := CalibrateStepLength(5, 0); got != 0 {
+		t.Errorf("CalibrateStepLength(5, 0) = %v, want 0", got)
+	}
+	if got := CalibrateStepLength(0, 6500); got != 0 {
+		t.Errorf("CalibrateStepLength(0, 6500) = %v, want 0", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	training, err := New(WithType(RunningType), WithAction(5000), WithDuration(30*time.Minute), WithWeight(85))
+	if err != nil {
+		t.Fatalf("New() running error = %v", err)
+	}
+	r, ok := training.(Running)
+	if !ok {
+		t.Fatalf("New() returned %T, want Running", training)
+	}
+	if r.LenStep != LenStep {
+		t.Errorf("LenStep = %v, want default %v", r.LenStep, LenStep)
+	}
+	if got, want := r.Calories(), testRunning.Calories(); !almostEqual(got, want) {
+		t.Errorf("Calories() = %v, want %v", got, want)
+	}
+
+	training, err = New(WithType(WalkingType), WithAction(20000), WithDuration(3*time.Hour+45*time.Minute),
+		WithWeight(85), WithHeight(185))
+	if err != nil {
+		t.Fatalf("New() walking error = %v", err)
+	}
+	if got, want := training.Calories(), testWalking.Calories(); !almostEqual(got, want) {
+		t.Errorf("walking Calories() = %v, want %v", got, want)
+	}
+
+	training, err = New(WithType(SwimmingType), WithAction(2000), WithDuration(90*time.Minute),
+		WithWeight(85), WithPool(50, 5))
+	if err != nil {
+		t.Fatalf("New() swimming error = %v", err)
+	}
+	if s, ok := training.(Swimming); !ok || s.LenStep != SwimmingLenStep {
+		t.Errorf("New() swimming = %+v, want Swimming with default stroke length", training)
+	}
+
+	if _, err := New(WithType("Гребля")); err == nil {
+		t.Error("New() with unknown type: expected error")
+	}
+}
+
+func TestDailyTotalBurn(t *testing.T) {
+	sessions := []CaloriesCalculator{testRunning, testRunning}
+	if got, want := DailyTotalBurn(1800, sessions), 1800+2*testRunning.Calories(); !almostEqual(got, want) {
+		t.Errorf("DailyTotalBurn() = %v, want %v", got, want)
+	}
+	if got := DailyTotalBurn(1800, nil); got != 1800 {
+		t.Errorf("DailyTotalBurn() rest day = %v, want 1800", got)
+	}
+	if got := RestDayBurn(-1); got != 0 {
+		t.Errorf("RestDayBurn(-1) = %v, want 0", got)
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	intervals := testRunning
+	intervals.TrainingType = "Бег|интервалы"
+
+	var buf strings.Builder
+	if err := ExportMarkdown(&buf, []CaloriesCalculator{testRunning, intervals}); err != nil {
+		t.Fatalf("ExportMarkdown() error = %v", err)
+	}
+	want := "| Тип | Длительность, мин | Дистанция, км | Ккал |\n" +
+		"|---|---|---|---|\n" +
+		"| Бег | 30 | 3.25 | 302.91 |\n" +
+		"| Бег\\|интервалы | 30 | 3.25 | 302.91 |\n"
+	if got := buf.String(); got != want {
+		t.Errorf("ExportMarkdown() = %q, want %q", got, want)
+	}
+}
+
+func TestReplayAtWeight(t *testing.T) {
+	trainings := []CaloriesCalculator{testRunning, testSwimming}
+	heavy, light := ReplayAtWeight(trainings, 100), ReplayAtWeight(trainings, 70)
+	// Расход при беге и плавании пропорционален весу.
+	if !almostEqual(heavy/light, 100.0/70) {
+		t.Errorf("ReplayAtWeight() ratio = %v, want %v", heavy/light, 100.0/70)
+	}
+	if testRunning.Weight != 85 || testSwimming.Weight != 85 {
+		t.Error("ReplayAtWeight() modified the original trainings")
+	}
+	if got, want := ReplayAtWeight(trainings, 85), totalCalories(trainings); !almostEqual(got, want) {
+		t.Errorf("ReplayAtWeight() at the same weight = %v, want %v", got, want)
+	}
+}
+
+func TestGradeAdjustedPace(t *testing.T) {
+	r := newRunning(10000, 1, 50*time.Minute) // 5 мин/км
+	tests := []struct {
+		grade, want float64
+	}{
+		{0, 5},
+		{5, 5 / 1.165},
+		{-5, 5 / 0.91},
+		{-20, 5 / 0.82}, // ускорение на спуске ограничено 10%
+	}
+	for _, tt := range tests {
+		if got := r.GradeAdjustedPace(tt.grade); !almostEqual(got, tt.want) {
+			t.Errorf("GradeAdjustedPace(%v) = %v, want %v", tt.grade, got, tt.want)
+		}
+	}
+
+	r.ElevationGain = 100
+	if got := r.Grade(); !almostEqual(got, 1) {
+		t.Errorf("Grade() = %v, want 1", got)
+	}
+}
+
+func TestEquivalentStairFlights(t *testing.T) {
+	// 302.91 ккал / 1.5 ккал на пролет ≈ 202 пролета.
+	if got, want := EquivalentStairFlights(testRunning), testRunning.Calories()/1.5; !almostEqual(got, want) {
+		t.Errorf("EquivalentStairFlights() = %v, want %v", got, want)
+	}
+	if got := EquivalentStairFlights(testRunning); math.Round(got) != 202 {
+		t.Errorf("EquivalentStairFlights() = %v, want about 202", got)
+	}
+}
+
+func TestTotalElevationGain(t *testing.T) {
+	r, w := testRunning, testWalking
+	r.ElevationGain = 100
+	w.ElevationGain = 50
+	if got := TotalElevationGain([]CaloriesCalculator{r, w, testSwimming}); got != 150 {
+		t.Errorf("TotalElevationGain() = %v, want 150", got)
+	}
+}
+
+func TestPrometheusLines(t *testing.T) {
+	want := []string{
+		`workout_duration_minutes{type="Бег"} 30.00`,
+		`workout_distance_km{type="Бег"} 3.25`,
+		`workout_speed_kmh{type="Бег"} 6.50`,
+		`workout_calories_kcal{type="Бег"} 302.91`,
+	}
+	got := testRunning.TrainingInfo().PrometheusLines("")
+	if len(got) != len(want) {
+		t.Fatalf("PrometheusLines() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	info := InfoMessage{TrainingType: `Бег "вверх"`}
+	if got, want := info.PrometheusLines("gym")[0], `gym_duration_minutes{type="Бег \"вверх\""} 0.00`; got != want {
+		t.Errorf("PrometheusLines() escaped = %q, want %q", got, want)
+	}
+}
+
+func TestRequiredPace(t *testing.T) {
+	if got, want := RequiredPace(42.195, 4*time.Hour), 240/42.195; !almostEqual(got, want) {
+		t.Errorf("RequiredPace(42.195, 4h) = %v, want %v", got, want)
+	}
+	if got := RequiredPace(0, time.Hour); got != 0 {
+		t.Errorf("RequiredPace(0, 1h) = %v, want 0", got)
+	}
+	if got := RequiredPace(10, 0); got != 0 {
+		t.Errorf("RequiredPace(10, 0) = %v, want 0", got)
+	}
+}
+
+func TestIsPersonalBest(t *testing.T) {
+	history := []CaloriesCalculator{
+		newRunning(5000, 1, 30*time.Minute),
+		newRunning(8000, 1, 60*time.Minute),
+	}
+	r := newRunning(6000, 1, 30*time.Minute) // 12 км/ч, 6 км
+
+	if !IsPersonalBest(r, history, MetricSpeed) {
+		t.Error("IsPersonalBest(speed) = false, want true")
+	}
+	if IsPersonalBest(r, history, MetricDistance) {
+		t.Error("IsPersonalBest(distance) = true, want false")
+	}
+	if !IsPersonalBest(r, nil, MetricDistance) {
+		t.Error("IsPersonalBest() with empty history = false, want true")
+	}
+}
+
+func TestIntensityProxy(t *testing.T) {
+	tests := []struct {
+		action int
+		want   float64
+	}{
+		{2000, 0},
+		{4000, 0},
+		{10000, 0.5},
+		{16000, 1},
+		{20000, 1},
+	}
+	for _, tt := range tests {
+		r := newRunning(tt.action, 1, time.Hour)
+		if got := IntensityProxy(r); !almostEqual(got, tt.want) {
+			t.Errorf("IntensityProxy() at %v km/h = %v, want %v", tt.action/1000, got, tt.want)
+		}
+	}
+}
+
+func TestShareText(t *testing.T) {
+	if got, want := testRunning.TrainingInfo().ShareText(), "🏃 3.25 км за 30 мин, 303 ккал!"; got != want {
+		t.Errorf("ShareText() = %q, want %q", got, want)
+	}
+	info := InfoMessage{TrainingType: "Йога"}
+	if got, want := info.ShareText(), "💪 0.00 км за 0 мин, 0 ккал!"; got != want {
+		t.Errorf("ShareText() for unknown type = %q, want %q", got, want)
+	}
+	info = InfoMessage{TrainingType: RunningType, Calories: -1e-15}
+	if got, want := info.ShareText(), "🏃 0.00 км за 0 мин, 0 ккал!"; got != want {
+		t.Errorf("ShareText() with negative zero = %q, want %q", got, want)
+	}
+}
+
+func TestRollingBests(t *testing.T) {
+	var trainings []CaloriesCalculator
+	for _, action := range []int{3000, 5000, 4000, 6000} {
+		trainings = append(trainings, newRunning(action, 1, 30*time.Minute))
+	}
+	got := RollingBests(trainings, MetricDistance)
+	want := []float64{3, 5, 5, 6}
+	if len(got) != len(want) {
+		t.Fatalf("RollingBests() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("RollingBests()[%d] = %v, want %v", i, got[i], want[i])
+		}
+		if i > 0 && got[i] < got[i-1] {
+			t.Errorf("RollingBests() is not monotonic at %d: %v", i, got)
+		}
+	}
+}
+
+func TestGlycogenDepletionPercent(t *testing.T) {
+	long := newRunning(20000, 1, 2*time.Hour)
+	// 1854.3 ккал из запаса 25 * 85 = 2125 ккал.
+	if got, want := GlycogenDepletionPercent(long), long.Calories()/2125*100; !almostEqual(got, want) {
+		t.Errorf("GlycogenDepletionPercent() = %v, want %v", got, want)
+	}
+	if got := GlycogenDepletionPercent(long); got < 85 || got > 90 {
+		t.Errorf("GlycogenDepletionPercent() = %v, want about 87", got)
+	}
+	ultra := newRunning(40000, 1, 4*time.Hour)
+	if got := GlycogenDepletionPercent(ultra); got != 100 {
+		t.Errorf("GlycogenDepletionPercent() for ultra = %v, want capped 100", got)
+	}
+}
+
+func TestAverageCadence(t *testing.T) {
+	// 5000 шагов за 30 минут и 20000 шагов за 225 минут.
+	want := (5000.0/30 + 20000.0/225) / 2
+	if got := AverageCadence([]CaloriesCalculator{testRunning, testWalking, testSwimming}); !almostEqual(got, want) {
+		t.Errorf("AverageCadence() = %v, want %v", got, want)
+	}
+	if got := AverageCadence([]CaloriesCalculator{testSwimming}); got != 0 {
+		t.Errorf("AverageCadence() for swimming only = %v, want 0", got)
+	}
+	if got := AverageCadence(nil); got != 0 {
+		t.Errorf("AverageCadence(nil) = %v, want 0", got)
+	}
+}
+
+func TestVarietyScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		trainings []CaloriesCalculator
+		want      float64
+	}{
+		{"single type", []CaloriesCalculator{testRunning, testRunning}, 0},
+		{"two balanced", []CaloriesCalculator{testRunning, testWalking}, 1},
+		{"three balanced", []CaloriesCalculator{testRunning, testWalking, testSwimming}, 1},
+		{"empty", nil, 0},
+	}
+	for _, tt := range tests {
+		if got := VarietyScore(tt.trainings); !almostEqual(got, tt.want) {
+			t.Errorf("%s: VarietyScore() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	unbalanced := VarietyScore([]CaloriesCalculator{testRunning, testRunning, testRunning, testWalking})
+	if unbalanced <= 0 || unbalanced >= 1 {
+		t.Errorf("VarietyScore() unbalanced = %v, want between 0 and 1", unbalanced)
+	}
+}
+
+func TestPauses(t *testing.T) {
+	paused := newRunning(5000, LenStep, 40*time.Minute)
+	paused.Pauses = []time.Duration{4 * time.Minute, 6 * time.Minute}
+
+	if got := paused.movingTime(); got != 30*time.Minute {
+		t.Errorf("movingTime() = %v, want 30m", got)
+	}
+	info := paused.TrainingInfo()
+	if info.Duration != 40*time.Minute {
+		t.Errorf("Duration = %v, want elapsed 40m", info.Duration)
+	}
+	if !almostEqual(info.Speed, 6.5) {
+		t.Errorf("Speed = %v, want 6.5 by moving time", info.Speed)
+	}
+	if got, want := paused.Calories(), testRunning.Calories(); !almostEqual(got, want) {
+		t.Errorf("Calories() = %v, want %v as for 30 minutes without pauses", got, want)
+	}
+
+	paused.Pauses = []time.Duration{time.Hour}
+	if got := paused.meanSpeed(); got != 0 {
+		t.Errorf("meanSpeed() with pauses longer than training = %v, want 0", got)
+	}
+
+	negative := newRunning(5000, LenStep, 30*time.Minute)
+	negative.Pauses = []time.Duration{-time.Hour, 0}
+	if got := negative.movingTime(); got != 30*time.Minute {
+		t.Errorf("movingTime() with negative pause = %v, want 30m", got)
+	}
+	if got, want := negative.Calories(), testRunning.Calories(); !almostEqual(got, want) {
+		t.Errorf("Calories() with negative pause = %v, want %v", got, want)
+	}
+}
+
+func TestPausesExcludedFromDerivedMetrics(t *testing.T) {
+	paused := newRunning(5000, LenStep, 40*time.Minute)
+	paused.Pauses = []time.Duration{10 * time.Minute}
+	paused.AvgHeartRate = 130
+	plain := testRunning
+	plain.AvgHeartRate = 130
+
+	if got, want := paused.Cadence(), plain.Cadence(); !almostEqual(got, want) {
+		t.Errorf("Cadence() = %v, want %v", got, want)
+	}
+	if got, want := paused.TRIMP(200, 40, true), plain.TRIMP(200, 40, true); !almostEqual(got, want) {
+		t.Errorf("TRIMP() = %v, want %v", got, want)
+	}
+	if got, want := EffortScore(paused), EffortScore(plain); !almostEqual(got, want) {
+		t.Errorf("EffortScore() = %v, want %v", got, want)
+	}
+	if got, want := TimeToBurn(paused, 500), TimeToBurn(plain, 500); got != want {
+		t.Errorf("TimeToBurn() = %v, want %v", got, want)
+	}
+	if got, want := paused.PredictTime(10), plain.PredictTime(10); got != want {
+		t.Errorf("PredictTime() = %v, want %v", got, want)
+	}
+	if got, want := paused.VsPacer(6), plain.VsPacer(6)-10*time.Minute; got != want {
+		t.Errorf("VsPacer() = %v, want %v by elapsed time", got, want)
+	}
+}
+
+func TestAgeGradedPercent(t *testing.T) {
+	r := newRunning(5000, 1, 25*time.Minute) // 12 км/ч
+	young, older := r.AgeGradedPercent(30, true), r.AgeGradedPercent(60, true)
+	if want := 12 / AgeGradeMaleOpenSpeed * 100; !almostEqual(young, want) {
+		t.Errorf("AgeGradedPercent(30) = %v, want %v", young, want)
+	}
+	if !almostEqual(older, young/0.84) {
+		t.Errorf("AgeGradedPercent(60) = %v, want %v", older, young/0.84)
+	}
+	if got := r.AgeGradedPercent(0, true); got != 0 {
+		t.Errorf("AgeGradedPercent(0) = %v, want 0", got)
+	}
+	if got := newRunning(10000, 1, 50*time.Minute).AgeGradedPercent(30, true); got != 0 {
+		t.Errorf("AgeGradedPercent() for 10 km = %v, want 0", got)
+	}
+}
+
+func TestOneRepMaxEstimate(t *testing.T) {
+	if got := OneRepMaxEstimate(100, 10); !almostEqual(got, 400.0/3) {
+		t.Errorf("OneRepMaxEstimate(100, 10) = %v, want %v", got, 400.0/3)
+	}
+	if got := OneRepMaxEstimate(100, 0); got != 0 {
+		t.Errorf("OneRepMaxEstimate(100, 0) = %v, want 0", got)
+	}
+	if got := OneRepMaxEstimate(-5, 10); got != 0 {
+		t.Errorf("OneRepMaxEstimate(-5, 10) = %v, want 0", got)
+	}
+}
+
+func TestWeeklyCaloriesByDay(t *testing.T) {
+	var days [7][]CaloriesCalculator
+	days[0] = []CaloriesCalculator{testRunning, testSwimming}
+	days[3] = []CaloriesCalculator{testWalking}
+
+	got := WeeklyCaloriesByDay(days)
+	want := [7]float64{testRunning.Calories() + testSwimming.Calories(), 0, 0, testWalking.Calories()}
+	for i := range want {
+		if !almostEqual(got[i], want[i]) {
+			t.Errorf("day %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNormalizeToPoolLength(t *testing.T) {
+	s := newSwimming(10, time.Hour)
+	got := s.NormalizeToPoolLength(25)
+	if got.LengthPool != 25 || got.CountPool != 20 || got.PartialLength != 0 {
+		t.Errorf("NormalizeToPoolLength(25) = %d x %d + %v, want 25 x 20", got.LengthPool, got.CountPool, got.PartialLength)
+	}
+	if !almostEqual(got.Calories(), s.Calories()) {
+		t.Errorf("Calories() after normalization = %v, want %v", got.Calories(), s.Calories())
+	}
+
+	got = newSwimming(5, time.Hour).NormalizeToPoolLength(33)
+	if got.CountPool != 7 || !almostEqual(got.poolDistance(), 250) {
+		t.Errorf("NormalizeToPoolLength(33) = %d lengths + %v, want 7 and 250 m in total", got.CountPool, got.PartialLength)
+	}
+	if got := s.NormalizeToPoolLength(0); got.LengthPool != 50 {
+		t.Errorf("NormalizeToPoolLength(0) changed pool length to %d", got.LengthPool)
+	}
+}
+
+func TestCaloriesRange(t *testing.T) {
+	calories := testRunning.Calories()
+	low, high := CaloriesRange(testRunning)
+	if !almostEqual(low, calories*0.85) || !almostEqual(high, calories*1.15) {
+		t.Errorf("CaloriesRange() = (%v, %v), want (%v, %v)", low, high, calories*0.85, calories*1.15)
+	}
+	if low > calories || high < calories {
+		t.Errorf("CaloriesRange() = (%v, %v) does not bracket %v", low, high, calories)
+	}
+}
+
+func TestAdherence(t *testing.T) {
+	planned := []CaloriesCalculator{
+		newRunning(5000, 1, 30*time.Minute),
+		newRunning(10000, 1, time.Hour),
+	}
+	tests := []struct {
+		name   string
+		actual []CaloriesCalculator
+		want   float64
+	}{
+		{"full", []CaloriesCalculator{newRunning(5200, 1, 31*time.Minute), newRunning(9500, 1, time.Hour)}, 1},
+		{"partial", []CaloriesCalculator{newRunning(5000, 1, 30*time.Minute), newRunning(5000, 1, 30*time.Minute)}, 0.5},
+		{"wrong type", []CaloriesCalculator{newWalking(5000, 1, time.Hour)}, 0},
+		{"none", nil, 0},
+	}
+	for _, tt := range tests {
+		if got := Adherence(planned, tt.actual); got != tt.want {
+			t.Errorf("%s: Adherence() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := Adherence(nil, planned); got != 0 {
+		t.Errorf("Adherence() with empty plan = %v, want 0", got)
+	}
+}
+
+func TestExportTCX(t *testing.T) {
+	r := testRunning
+	r.Date = time.Date(2024, time.March, 10, 7, 30, 0, 0, time.UTC)
+
+	var buf strings.Builder
+	if err := ExportTCX(&buf, r); err != nil {
+		t.Fatalf("ExportTCX() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<TrainingCenterDatabase xmlns="` + TCXNamespace + `">`,
+		`<Activity Sport="Running">`,
+		`<Id>2024-03-10T07:30:00Z</Id>`,
+		`<TotalTimeSeconds>1800</TotalTimeSeconds>`,
+		`<DistanceMeters>3250</DistanceMeters>`,
+		`<Calories>303</Calories>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ExportTCX() output is missing %q:\n%s", want, got)
+		}
+	}
+
+	if err := ExportTCX(&buf, newRunning(5000, LenStep, 0)); err == nil {
+		t.Error("ExportTCX() for invalid training: expected error")
+	}
+	buf.Reset()
+	if err := ExportTCX(&buf, testRunning); err == nil {
+		t.Error("ExportTCX() for training without date: expected error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("ExportTCX() for training without date wrote %q", buf.String())
+	}
+}
+
+func TestSweatLossLiters(t *testing.T) {
+	// Интенсивность бега 6.5 км/ч: (6.5 - 4) / 12; за полчаса при 20 °C.
+	want := (0.5 + 2.5/12) * 0.5
+	if got := SweatLossLiters(testRunning, 20); !almostEqual(got, want) {
+		t.Errorf("SweatLossLiters(20) = %v, want %v", got, want)
+	}
+	hot, cold := SweatLossLiters(testRunning, 35), SweatLossLiters(testRunning, 0)
+	if hot <= want || cold >= want {
+		t.Errorf("SweatLossLiters() hot %v, comfortable %v, cold %v: want hot > comfortable > cold", hot, want, cold)
+	}
+	if got := SweatLossLiters(testRunning, -40); !almostEqual(got, want*0.5) {
+		t.Errorf("SweatLossLiters(-40) = %v, want floor %v", got, want*0.5)
+	}
+}
+
+func TestFitnessTrendSlope(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"rising", []float64{1, 2, 3, 4}, 1},
+		{"falling", []float64{6, 4, 2}, -2},
+		{"flat", []float64{5, 5, 5}, 0},
+		{"single", []float64{5}, 0},
+	}
+	for _, tt := range tests {
+		if got := FitnessTrendSlope(tt.values); !almostEqual(got, tt.want) {
+			t.Errorf("%s: FitnessTrendSlope() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCaloriePercentByType(t *testing.T) {
+	trainings := []CaloriesCalculator{testRunning, testRunning, testWalking}
+	total := 2*testRunning.Calories() + testWalking.Calories()
+
+	got := CaloriePercentByType(trainings)
+	if want := 2 * testRunning.Calories() / total * 100; !almostEqual(got[RunningType], want) {
+		t.Errorf("running share = %v, want %v", got[RunningType], want)
+	}
+	if !almostEqual(got[RunningType]+got[WalkingType], 100) {
+		t.Errorf("shares sum to %v, want 100", got[RunningType]+got[WalkingType])
+	}
+	if got := CaloriePercentByType(nil); len(got) != 0 {
+		t.Errorf("CaloriePercentByType(nil) = %v, want empty", got)
+	}
+}
+
+func TestSmoothedMeanSpeed(t *testing.T) {
+	r := testRunning
+	if got := SmoothedMeanSpeed(r, 3); !almostEqual(got, 6.5) {
+		t.Errorf("SmoothedMeanSpeed() without points = %v, want 6.5", got)
+	}
+	// Одиночный выброс GPS убирается медианой.
+	r.SpeedPoints = []float64{10, 10, 50, 10, 10}
+	if got := SmoothedMeanSpeed(r, 3); !almostEqual(got, 10) {
+		t.Errorf("SmoothedMeanSpeed(3) = %v, want 10", got)
+	}
+	if got := SmoothedMeanSpeed(r, 1); !almostEqual(got, 18) {
+		t.Errorf("SmoothedMeanSpeed(1) = %v, want plain mean 18", got)
+	}
+}
+
+func TestDetectPlateau(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric []float64
+		want   bool
+	}{
+		{"improving", []float64{1, 2, 3, 4, 5}, false},
+		{"flat", []float64{3, 5, 5.1, 5, 5.05}, true},
+		{"too short", []float64{5, 5}, false},
+	}
+	for _, tt := range tests {
+		if got := DetectPlateau(tt.metric, 4, 0.2); got != tt.want {
+			t.Errorf("%s: DetectPlateau() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestComebackFactor(t *testing.T) {
+	if got := ComebackFactor(3); got != 1 {
+		t.Errorf("ComebackFactor(3) = %v, want 1", got)
+	}
+	want := 1 - 0.2*(1-math.Exp(-53.0/30))
+	if got := ComebackFactor(60); !almostEqual(got, want) {
+		t.Errorf("ComebackFactor(60) = %v, want %v", got, want)
+	}
+	if got := ComebackFactor(10000); !almostEqual(got, 0.8) {
+		t.Errorf("ComebackFactor(10000) = %v, want 0.8", got)
+	}
+
+	r := testRunning
+	r.DaysOff = 60
+	if got := r.Calories(); !almostEqual(got, testRunning.Calories()*want) {
+		t.Errorf("Calories() after 60 days off = %v, want %v", got, testRunning.Calories()*want)
+	}
+}
+
+func TestRecommendType(t *testing.T) {
+	p := Profile{Weight: 85, Height: 185}
+	tests := []struct {
+		goal float64
+		want string
+	}{
+		{280, RunningType},  // за 30 минут цели достигает только бег
+		{100, WalkingType},  // цели достигают все, ходьба — с наименьшим запасом
+		{5000, RunningType}, // цель недостижима, бег дает больше всего
+	}
+	for _, tt := range tests {
+		got, training := RecommendType(tt.goal, 30*time.Minute, p)
+		if got != tt.want {
+			t.Errorf("RecommendType(%v) = %q, want %q", tt.goal, got, tt.want)
+		}
+		if training.TrainingInfo().TrainingType != got {
+			t.Errorf("RecommendType(%v) returned %q training for %q", tt.goal, training.TrainingInfo().TrainingType, got)
+		}
+	}
+}
+
+func TestHistoryRoundTrip(t *testing.T) {
+	r := testRunning
+	r.Date = time.Date(2024, time.March, 10, 7, 30, 0, 0, time.UTC)
+	want := []CaloriesCalculator{r, testWalking, testSwimming}
+
+	data, err := MarshalHistory(want)
+	if err != nil {
+		t.Fatalf("MarshalHistory() error = %v", err)
+	}
+	got, err := UnmarshalHistory(data)
+	if err != nil {
+		t.Fatalf("UnmarshalHistory() error = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("UnmarshalHistory() returned %d trainings, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].TrainingInfo() != want[i].TrainingInfo() {
+			t.Errorf("training %d = %+v, want %+v", i, got[i].TrainingInfo(), want[i].TrainingInfo())
+		}
+	}
+	if !got[0].(Running).Date.Equal(r.Date) {
+		t.Errorf("Date = %v, want %v", got[0].(Running).Date, r.Date)
+	}
+
+	if _, err := UnmarshalHistory([]byte(`{"version":99,"sessions":[]}`)); err == nil {
+		t.Error("UnmarshalHistory() with unknown version: expected error")
+	}
+	if _, err := UnmarshalHistory([]byte(`{"version":1,"sessions":[{"kind":"rowing","data":{}}]}`)); err == nil {
+		t.Error("UnmarshalHistory() with unknown kind: expected error")
+	}
+}
+
+func TestBurnRateCurve(t *testing.T) {
+	rate := testRunning.Calories() / 30
+	for i, got := range BurnRateCurve(testRunning, 3) {
+		if !almostEqual(got, rate) {
+			t.Errorf("BurnRateCurve(3)[%d] = %v, want constant %v", i, got, rate)
+		}
+	}
+
+	r := testRunning
+	r.SpeedPoints = []float64{5, 15}
+	got := BurnRateCurve(r, 2)
+	if len(got) != 2 || !almostEqual(got[0], rate*0.5) || !almostEqual(got[1], rate*1.5) {
+		t.Errorf("BurnRateCurve(2) with intervals = %v, want [%v %v]", got, rate*0.5, rate*1.5)
+	}
+	if got := BurnRateCurve(r, 0); got != nil {
+		t.Errorf("BurnRateCurve(0) = %v, want nil", got)
+	}
+}
+
+func TestWeekOverWeek(t *testing.T) {
+	diff := WeekOverWeek([]CaloriesCalculator{testRunning, testRunning}, []CaloriesCalculator{testRunning})
+	if !almostEqual(diff.Distance, 3.25) || !almostEqual(diff.CaloriesPercent, 100) {
+		t.Errorf("WeekOverWeek() improvement = %+v, want +3.25 km and +100%% calories", diff)
+	}
+
+	diff = WeekOverWeek([]CaloriesCalculator{testRunning}, []CaloriesCalculator{testRunning, testRunning})
+	if !almostEqual(diff.DistancePercent, -50) {
+		t.Errorf("WeekOverWeek() decline DistancePercent = %v, want -50", diff.DistancePercent)
+	}
+
+	diff = WeekOverWeek([]CaloriesCalculator{testRunning}, nil)
+	if diff.CaloriesPercent != 0 || !almostEqual(diff.Calories, testRunning.Calories()) {
+		t.Errorf("WeekOverWeek() from empty week = %+v, want zero percent change", diff)
+	}
+}
+
+func TestRecommendedRecoveryHours(t *testing.T) {
+	easy := newWalking(2000, 1, 30*time.Minute)
+	hard := newRunning(15000, 1, time.Hour)
+	easyHours, hardHours := RecommendedRecoveryHours(easy), RecommendedRecoveryHours(hard)
+	if easyHours < RecoveryBaseHours || easyHours >= hardHours {
+		t.Errorf("RecommendedRecoveryHours() easy %v, hard %v: want base <= easy < hard", easyHours, hardHours)
+	}
+	if hardHours > RecoveryMaxHours {
+		t.Errorf("RecommendedRecoveryHours() = %v, want at most %v", hardHours, RecoveryMaxHours)
+	}
+
+	ultra := newRunning(60000, 1, 6*time.Hour)
+	if got := RecommendedRecoveryHours(ultra); got != RecoveryMaxHours {
+		t.Errorf("RecommendedRecoveryHours() for ultra = %v, want %v", got, RecoveryMaxHours)
+	}
+}
+
+func TestShortCodeRoundTrip(t *testing.T) {
+	for _, want := range []CaloriesCalculator{testRunning, testWalking, testSwimming} {
+		code, err := ToShortCode(want)
+		if err != nil {
+			t.Fatalf("ToShortCode() error = %v", err)
+		}
+		got, err := FromShortCode(code)
+		if err != nil {
+			t.Fatalf("FromShortCode(%q) error = %v", code, err)
+		}
+		if got.TrainingInfo() != want.TrainingInfo() {
+			t.Errorf("round trip = %+v, want %+v", got.TrainingInfo(), want.TrainingInfo())
+		}
+	}
+
+	valid, _ := ToShortCode(testRunning)
+	code := []byte(valid)
+	if code[5] == 'A' {
+		code[5] = 'B'
+	} else {
+		code[5] = 'A'
+	}
+	if _, err := FromShortCode(string(code)); err != ErrCorruptShortCode {
+		t.Errorf("FromShortCode() with damaged code error = %v, want ErrCorruptShortCode", err)
+	}
+	if _, err := FromShortCode("не код"); err != ErrCorruptShortCode {
+		t.Errorf("FromShortCode() with garbage error = %v, want ErrCorruptShortCode", err)
+	}
+	if _, err := ToShortCode(nil); err == nil {
+		t.Error("ToShortCode(nil): expected error")
+	}
+}
+
+func TestShortCodeKeepsCalorieFields(t *testing.T) {
+	run := newRunning(5000, LenStep, 40*time.Minute)
+	run.Pauses = []time.Duration{10 * time.Minute}
+	run.Date = time.Date(2024, 5, 1, 7, 30, 0, 0, time.UTC)
+	run.AvgHeartRate = 150
+	run.FatigueModel = true
+	run.DaysOff = 20
+	run.ElevationGain = 120
+	swim := newSwimming(5, 90*time.Minute)
+	swim.PartialLength = 0.5
+	swim.StrokesPerLength = 18
+
+	for _, want := range []CaloriesCalculator{run, swim} {
+		code, err := ToShortCode(want)
+		if err != nil {
+			t.Fatalf("ToShortCode() error = %v", err)
+		}
+		got, err := FromShortCode(code)
+		if err != nil {
+			t.Fatalf("FromShortCode(%q) error = %v", code, err)
+		}
+		if got.TrainingInfo() != want.TrainingInfo() {
+			t.Errorf("round trip = %+v, want %+v", got.TrainingInfo(), want.TrainingInfo())
+		}
+	}
+
+	code, _ := ToShortCode(run)
+	got, _ := FromShortCode(code)
+	decoded := got.(Running)
+	if !decoded.Date.Equal(run.Date) || decoded.AvgHeartRate != 150 || decoded.ElevationGain != 120 {
+		t.Errorf("round trip = %+v, want %+v", decoded, run)
+	}
+	code, _ = ToShortCode(swim)
+	got, _ = FromShortCode(code)
+	if decoded := got.(Swimming); decoded.StrokesPerLength != 18 || decoded.PartialLength != 0.5 {
+		t.Errorf("round trip = %+v, want %+v", decoded, swim)
+	}
+}
+
+func TestShortCodeRejectsNegative(t *testing.T) {
+	negativeAction := testRunning
+	negativeAction.Action = -1
+	negativeDuration := testWalking
+	negativeDuration.Duration = -time.Minute
+	negativePause := testRunning
+	negativePause.Pauses = []time.Duration{-time.Minute}
+	negativePool := testSwimming
+	negativePool.CountPool = -5
+
+	for _, training := range []CaloriesCalculator{negativeAction, negativeDuration, negativePause, negativePool} {
+		if _, err := ToShortCode(training); !errors.Is(err, ErrShortCodeValue) {
+			t.Errorf("ToShortCode(%+v) error = %v, want ErrShortCodeValue", training, err)
+		}
+	}
+}
+
+func TestFoodEquivalents(t *testing.T) {
+	got := InfoMessage{Calories: 460}.FoodEquivalents()
+	if len(got) != len(foodCalories) {
+		t.Errorf("FoodEquivalents() returned %d foods, want %d", len(got), len(foodCalories))
+	}
+	if !almostEqual(got["шоколадный батончик"], 2) {
+		t.Errorf("шоколадный батончик = %v, want 2", got["шоколадный батончик"])
+	}
+	if !almostEqual(got["банан"], 460.0/105) {
+		t.Errorf("банан = %v, want %v", got["банан"], 460.0/105)
+	}
+}
+
+func TestEstimatedSteps(t *testing.T) {
+	if got := EstimatedSteps(testRunning); got != 5000 {
+		t.Errorf("EstimatedSteps(Running) = %d, want 5000", got)
+	}
+	if got, want := EstimatedSteps(testSwimming), int(math.Round(testSwimming.Calories()*StepsPerCalorie)); got != want {
+		t.Errorf("EstimatedSteps(Swimming) = %d, want %d", got, want)
+	}
+	// Заплыв с тем же расходом, что и бег, засчитывается как 20 шагов на килокалорию.
+	s := testSwimming
+	s.Weight *= testRunning.Calories() / testSwimming.Calories()
+	if got, want := EstimatedSteps(s), int(math.Round(testRunning.Calories()*StepsPerCalorie)); got != want {
+		t.Errorf("EstimatedSteps(Swimming) for equal calories = %d, want %d", got, want)
+	}
+}
+
+func TestCaloriesPerDollar(t *testing.T) {
+	if got, want := CaloriesPerDollar(testSwimming, 10), testSwimming.Calories()/10; !almostEqual(got, want) {
+		t.Errorf("CaloriesPerDollar(10) = %v, want %v", got, want)
+	}
+	if got := CaloriesPerDollar(testRunning, 0); got != 0 {
+		t.Errorf("CaloriesPerDollar(0) = %v, want 0", got)
+	}
+}
+
+func TestNormalizedEffortCalories(t *testing.T) {
+	hard := newRunning(8000, 1, 30*time.Minute) // 16 км/ч, интенсивность 1
+	easy := newWalking(4800, 1, 90*time.Minute) // 3.2 км/ч, интенсивность 0.2
+	if got := NormalizedEffortCalories(hard); !almostEqual(got, 360) {
+		t.Errorf("hard NormalizedEffortCalories() = %v, want 360", got)
+	}
+	if got := NormalizedEffortCalories(easy); !almostEqual(got, 360) {
+		t.Errorf("easy NormalizedEffortCalories() = %v, want 360", got)
+	}
+}
+
+func TestBreakEvenDuration(t *testing.T) {
+	// 323 ккал плавания при 4.21 ккал/мин ходьбы — около 76.7 минуты.
+	rate := testWalking.Calories() / 225
+	want := time.Duration(testSwimming.Calories() / rate * float64(time.Minute))
+	if got := BreakEvenDuration(testSwimming, testWalking); (got - want).Abs() > time.Millisecond {
+		t.Errorf("BreakEvenDuration() = %v, want %v", got, want)
+	}
+	if got := BreakEvenDuration(testSwimming, newRunning(0, LenStep, 0)); got != 0 {
+		t.Errorf("BreakEvenDuration() against idle training = %v, want 0", got)
+	}
+}
+
+func TestMilestoneCrossed(t *testing.T) {
+	milestones := []float64{100, 250, 500}
+	session := newRunning(10000, 1, time.Hour)
+
+	if got := MilestoneCrossed(95, session, milestones); len(got) != 1 || got[0] != 100 {
+		t.Errorf("MilestoneCrossed(95) = %v, want [100]", got)
+	}
+	long := newRunning(200000, 1, 24*time.Hour)
+	if got := MilestoneCrossed(90, long, milestones); len(got) != 2 || got[0] != 100 || got[1] != 250 {
+		t.Errorf("MilestoneCrossed(90) long = %v, want [100 250]", got)
+	}
+	if got := MilestoneCrossed(100, session, milestones); len(got) != 0 {
+		t.Errorf("MilestoneCrossed(100) = %v, want none", got)
+	}
+}
+
+func TestPartialCalories(t *testing.T) {
+	calories := testRunning.Calories()
+	tests := []struct {
+		fraction, want float64
+	}{
+		{0.5, calories / 2},
+		{1, calories},
+		{1.5, calories},
+		{-1, 0},
+	}
+	for _, tt := range tests {
+		if got := PartialCalories(testRunning, tt.fraction); !almostEqual(got, tt.want) {
+			t.Errorf("PartialCalories(%v) = %v, want %v", tt.fraction, got, tt.want)
+		}
+	}
+}
+
+func TestAdjustedTarget(t *testing.T) {
+	tests := []struct {
+		readiness, want float64
+	}{
+		{0, 250},
+		{0.5, 375},
+		{1, 500},
+		{2, 500},
+		{-1, 250},
+	}
+	for _, tt := range tests {
+		if got := AdjustedTarget(500, tt.readiness); !almostEqual(got, tt.want) {
+			t.Errorf("AdjustedTarget(500, %v) = %v, want %v", tt.readiness, got, tt.want)
+		}
+	}
+}
+
+func TestAltText(t *testing.T) {
+	want := "Карточка тренировки: Бег, дистанция 3.25 км за 30 мин, потрачено 303 ккал."
+	if got := testRunning.TrainingInfo().AltText(); got != want {
+		t.Errorf("AltText() = %q, want %q", got, want)
+	}
+	info := InfoMessage{TrainingType: RunningType, Calories: -1e-15}
+	want = "Карточка тренировки: Бег, дистанция 0.00 км за 0 мин, потрачено 0 ккал."
+	if got := info.AltText(); got != want {
+		t.Errorf("AltText() with negative zero = %q, want %q", got, want)
+	}
+}
+
+func TestPercentOfThreshold(t *testing.T) {
+	r := newRunning(10000, 1, 50*time.Minute) // 5 мин/км
+	if got := r.PercentOfThreshold(4.5); !almostEqual(got, 90) {
+		t.Errorf("PercentOfThreshold(4.5) = %v, want 90", got)
+	}
+	if got := r.PercentOfThreshold(5.5); !almostEqual(got, 110) {
+		t.Errorf("PercentOfThreshold(5.5) = %v, want 110", got)
+	}
+	if got := r.PercentOfThreshold(0); got != 0 {
+		t.Errorf("PercentOfThreshold(0) = %v, want 0", got)
+	}
+}
+
+func TestVoiceSummary(t *testing.T) {
+	want := "Вы пробежали три километра двести пятьдесят метров за тридцать минут и потратили триста три килокалории"
+	if got := testRunning.TrainingInfo().VoiceSummary(); got != want {
+		t.Errorf("VoiceSummary() = %q, want %q", got, want)
+	}
+
+	info := InfoMessage{TrainingType: SwimmingType, Distance: 0.45, Duration: 21 * time.Minute, Calories: 2000}
+	want = "Вы проплыли четыреста пятьдесят метров за двадцать одну минуту и потратили две тысячи килокалорий"
+	if got := info.VoiceSummary(); got != want {
+		t.Errorf("VoiceSummary() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		distance float64
+		want     string
+	}{
+		{1.5, "один километр пятьсот метров"},
+		{1.4, "один километр четыреста метров"},
+		{2.0004, "два километра"},
+		{9.998, "десять километров"},
+		{21.3, "двадцать один километр"},
+	}
+	for _, tt := range tests {
+		info := InfoMessage{TrainingType: WalkingType, Distance: tt.distance, Duration: time.Hour, Calories: 100}
+		want := "Вы прошли " + tt.want + " за шестьдесят минут и потратили сто килокалорий"
+		if got := info.VoiceSummary(); got != want {
+			t.Errorf("VoiceSummary() for %v km = %q, want %q", tt.distance, got, want)
+		}
+	}
+}
+
+func TestSpellNumber(t *testing.T) {
+	tests := []struct {
+		n        int
+		feminine bool
+		want     string
+	}{
+		{0, false, "ноль"},
+		{1, false, "один"},
+		{1, true, "одну"},
+		{12, false, "двенадцать"},
+		{42, true, "сорок две"},
+		{115, false, "сто пятнадцать"},
+		{1000, false, "тысячу"},
+		{1001, true, "тысячу одну"},
+		{21000, false, "двадцать одну тысячу"},
+		{2022, true, "две тысячи двадцать две"},
+		{5000, false, "пять тысяч"},
+		{1000000, false, "1000000"},
+	}
+	for _, tt := range tests {
+		if got := spellNumber(tt.n, tt.feminine); got != tt.want {
+			t.Errorf("spellNumber(%d, %v) = %q, want %q", tt.n, tt.feminine, got, tt.want)
+		}
+	}
+}
+
+func TestFuelMix(t *testing.T) {
+	fat, carb := FuelMix(newRunning(3000, 1, time.Hour))
+	if !almostEqual(fat, 70) || !almostEqual(carb, 30) {
+		t.Errorf("FuelMix() easy = (%v, %v), want (70, 30)", fat, carb)
+	}
+	fat, carb = FuelMix(newRunning(16000, 1, time.Hour))
+	if !almostEqual(fat, 10) || !almostEqual(carb, 90) {
+		t.Errorf("FuelMix() hard = (%v, %v), want (10, 90)", fat, carb)
+	}
+	fat, carb = FuelMix(testRunning)
+	if !almostEqual(fat+carb, 100) {
+		t.Errorf("FuelMix() = (%v, %v), want sum 100", fat, carb)
+	}
+}
+
+func TestEquivalentWalkingKm(t *testing.T) {
+	// Ходьба в собственном темпе соответствует собственной дистанции.
+	if got := EquivalentWalkingKm(testWalking); !almostEqual(got, 13) {
+		t.Errorf("EquivalentWalkingKm(Walking) = %v, want 13", got)
+	}
+	// 5 км/ч = 1.39 м/с при росте 170 см: (0.035 * 85 + 1.39^2 / 1.7 * 0.029 * 85) * 60 / 5 ккал/км.
+	perKm := (0.035*85 + 1.39*1.39/1.7*0.029*85) * 60 / 5
+	if got, want := EquivalentWalkingKm(testSwimming), 323/perKm; !almostEqual(got, want) {
+		t.Errorf("EquivalentWalkingKm(Swimming) = %v, want %v", got, want)
+	}
+	if got := EquivalentWalkingKm(Running{}); got != 0 {
+		t.Errorf("EquivalentWalkingKm() without weight = %v, want 0", got)
+	}
+}
+
+func TestEnergyDensity(t *testing.T) {
+	light, heavy := testRunning, testRunning
+	light.Weight, heavy.Weight = 60, 90
+	// Расход при беге пропорционален весу, поэтому удельный расход у обоих одинаковый.
+	if !almostEqual(EnergyDensity(light), EnergyDensity(heavy)) {
+		t.Errorf("EnergyDensity() 60 kg = %v, 90 kg = %v, want equal", EnergyDensity(light), EnergyDensity(heavy))
+	}
+	if want := testRunning.Calories() / 3.25 / 85; !almostEqual(EnergyDensity(testRunning), want) {
+		t.Errorf("EnergyDensity() = %v, want %v", EnergyDensity(testRunning), want)
+	}
+}
+
+func TestForecastMonthlyCalories(t *testing.T) {
+	recent := []CaloriesCalculator{testRunning, testWalking, testSwimming}
+	if got, want := ForecastMonthlyCalories(recent, 7), totalCalories(recent)/7*30; !almostEqual(got, want) {
+		t.Errorf("ForecastMonthlyCalories(7) = %v, want %v", got, want)
+	}
+	if got := ForecastMonthlyCalories(recent, 0); got != 0 {
+		t.Errorf("ForecastMonthlyCalories(0) = %v, want 0", got)
+	}
+}
+
+func TestMostImproved(t *testing.T) {
+	slow := newRunning(5000, 1, 30*time.Minute) // 10 км/ч
+	fast := newRunning(5000, 1, 20*time.Minute) // 15 км/ч
+	metric, change := MostImproved([]CaloriesCalculator{slow, slow, fast, fast})
+	if metric != MetricSpeed || !almostEqual(change, 50) {
+		t.Errorf("MostImproved() = %v, %v; want speed, 50", metric, change)
+	}
+	if metric, change := MostImproved([]CaloriesCalculator{slow}); metric != MetricDistance || change != 0 {
+		t.Errorf("MostImproved() for one training = %v, %v; want distance, 0", metric, change)
+	}
+}
+
+func TestTargetHRRange(t *testing.T) {
+	tests := []struct {
+		age       int
+		low, high int
+	}{
+		{30, 95, 162},
+		{60, 80, 136},
+		{0, 0, 0},
+		{230, 0, 0},
+	}
+	for _, tt := range tests {
+		if low, high := TargetHRRange(tt.age); low != tt.low || high != tt.high {
+			t.Errorf("TargetHRRange(%d) = (%d, %d), want (%d, %d)", tt.age, low, high, tt.low, tt.high)
+		}
+	}
+}
+
+func TestExtraCaloriesVsSedentary(t *testing.T) {
+	// 1680 ккал в сутки: 70 ккал/ч * 1.2 * 0.5 ч = 42 ккал в покое.
+	if got, want := ExtraCaloriesVsSedentary(testRunning, 1680), testRunning.Calories()-42; !almostEqual(got, want) {
+		t.Errorf("ExtraCaloriesVsSedentary(1680) = %v, want %v", got, want)
+	}
+	if got := ExtraCaloriesVsSedentary(testRunning, 100000); got != 0 {
+		t.Errorf("ExtraCaloriesVsSedentary() with huge BMR = %v, want 0", got)
+	}
+}
+
+func TestConsistencyScore(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 18, 0, 0, 0, time.UTC)
+	var daily, sporadic []time.Time
+	for i := 0; i < 7; i++ {
+		daily = append(daily, now.AddDate(0, 0, -i))
+	}
+	sporadic = []time.Time{now, now, now.AddDate(0, 0, -3), now.AddDate(0, 0, -30)}
+
+	if got := consistencyScore(daily, 7, now, nil); got != 1 {
+		t.Errorf("consistencyScore() daily = %v, want 1", got)
+	}
+	if got := consistencyScore(sporadic, 7, now, nil); !almostEqual(got, 2.0/7) {
+		t.Errorf("consistencyScore() sporadic = %v, want %v", got, 2.0/7)
+	}
+	if got := consistencyScore(daily, 0, now, nil); got != 0 {
+		t.Errorf("consistencyScore() with zero period = %v, want 0", got)
+	}
+
+	// 23:30 UTC 3 марта попадает в 4 марта по UTC+3 — первый день недельного периода.
+	moscow := time.FixedZone("MSK", 3*60*60)
+	late := []time.Time{time.Date(2024, time.March, 3, 23, 30, 0, 0, time.UTC)}
+	if got := consistencyScore(late, 7, now, moscow); !almostEqual(got, 1.0/7) {
+		t.Errorf("consistencyScore() in UTC+3 = %v, want %v", got, 1.0/7)
+	}
+	if got := consistencyScore(late, 7, now, nil); got != 0 {
+		t.Errorf("consistencyScore() in UTC = %v, want 0", got)
+	}
+}
+
+func TestPredictWithFade(t *testing.T) {
+	r := newRunning(10000, 1, 50*time.Minute) // 5 мин/км
+	if got := r.PredictWithFade(21, 0); (got - 105*time.Minute).Abs() > time.Millisecond {
+		t.Errorf("PredictWithFade(21, 0) = %v, want 1h45m", got)
+	}
+	if got := r.PredictWithFade(21, 20); (got - 115*time.Minute - 30*time.Second).Abs() > time.Millisecond {
+		t.Errorf("PredictWithFade(21, 20) = %v, want 1h55m30s", got)
+	}
+	if got := r.PredictWithFade(21, -10); (got - 105*time.Minute).Abs() > time.Millisecond {
+		t.Errorf("PredictWithFade(21, -10) = %v, want fade ignored", got)
+	}
+}
+
+func TestOpenWaterAdjustedDistance(t *testing.T) {
+	s := newSwimming(0, time.Hour) // 2000 гребков по 1.38 м
+	if got := s.OpenWaterAdjustedDistance(1); !almostEqual(got, 2.76) {
+		t.Errorf("OpenWaterAdjustedDistance(1) = %v, want 2.76", got)
+	}
+	if got := s.OpenWaterAdjustedDistance(1.2); !almostEqual(got, 2.3) {
+		t.Errorf("OpenWaterAdjustedDistance(1.2) = %v, want 2.3", got)
+	}
+	if got := s.OpenWaterAdjustedDistance(0.8); !almostEqual(got, 3.45) {
+		t.Errorf("OpenWaterAdjustedDistance(0.8) = %v, want 3.45 against the current", got)
+	}
+	if got := s.OpenWaterAdjustedDistance(0); !almostEqual(got, 2.76) {
+		t.Errorf("OpenWaterAdjustedDistance(0) = %v, want unadjusted 2.76", got)
+	}
+}
+
+func TestFormatFloatNegativeZero(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{-1e-15, "0.00"},
+		{math.Copysign(0, -1), "0.00"},
+		{-0.004, "0.00"},
+		{3.14159, "3.14"},
+	}
+	for _, tt := range tests {
+		if got := formatFloat(tt.v); got != tt.want {
+			t.Errorf("formatFloat(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+
+	info := InfoMessage{TrainingType: RunningType, Calories: -1e-15}
+	if got := info.String(); !strings.Contains(got, "Потрачено ккал: 0.00\n") {
+		t.Errorf("String() = %q, want calories printed as 0.00", got)
+	}
+}
+
+func TestCombinedStrain(t *testing.T) {
+	sessions := []CaloriesCalculator{testRunning, testSwimming}
+	separate := CombinedStrain(sessions, false)
+	if want := EffortScore(testRunning) + EffortScore(testSwimming); !almostEqual(separate, want) {
+		t.Errorf("CombinedStrain() on different days = %v, want %v", separate, want)
+	}
+	if got := CombinedStrain(sessions, true); !almostEqual(got, separate*1.1) {
+		t.Errorf("CombinedStrain() on the same day = %v, want %v", got, separate*1.1)
+	}
+	single := []CaloriesCalculator{testRunning}
+	if CombinedStrain(single, true) != CombinedStrain(single, false) {
+		t.Error("CombinedStrain() for a single session depends on sameDay")
+	}
+}
+
+func TestToFITFields(t *testing.T) {
+	r := testRunning
+	r.Duration = 40 * time.Minute
+	r.Pauses = []time.Duration{10 * time.Minute}
+
+	got := ToFITFields(r)
+	if got["sport"] != "running" {
+		t.Errorf("sport = %v, want running", got["sport"])
+	}
+	if got["total_elapsed_time"] != 2400.0 || got["total_timer_time"] != 1800.0 {
+		t.Errorf("elapsed/timer time = %v/%v, want 2400/1800", got["total_elapsed_time"], got["total_timer_time"])
+	}
+	if got["total_distance"] != 3250.0 {
+		t.Errorf("total_distance = %v, want 3250", got["total_distance"])
+	}
+	if got["total_calories"] != uint16(303) {
+		t.Errorf("total_calories = %v, want 303", got["total_calories"])
+	}
+	if speed, _ := got["avg_speed"].(float64); !almostEqual(speed, 6.5/3.6) {
+		t.Errorf("avg_speed = %v, want %v m/s", got["avg_speed"], 6.5/3.6)
+	}
+
+	got = ToFITFields(customTraining{})
+	if got["total_elapsed_time"] != 3600.0 || got["total_timer_time"] != 3600.0 {
+		t.Errorf("custom elapsed/timer time = %v/%v, want 3600/3600", got["total_elapsed_time"], got["total_timer_time"])
+	}
+}
+
+func TestPercentOfMarathon(t *testing.T) {
+	half := newRunning(42195, 0.5, 2*time.Hour)
+	if got := PercentOfMarathon(half); !almostEqual(got, 50) {
+		t.Errorf("PercentOfMarathon() for half marathon = %v, want 50", got)
+	}
+	if got := PercentOfMarathon(newRunning(0, LenStep, time.Hour)); got != 0 {
+		t.Errorf("PercentOfMarathon() for zero distance = %v, want 0", got)
+	}
+}
+
+func TestMinCaloriesPerMinute(t *testing.T) {
+	t.Cleanup(func() { SetMinCaloriesPerMinute(0) })
+
+	slow := newWalking(500, 1, time.Hour)
+	base := slow.Calories()
+	SetMinCaloriesPerMinute(base/60 + 1)
+	if got, want := slow.Calories(), (base/60+1)*60; !almostEqual(got, want) {
+		t.Errorf("Calories() with floor = %v, want %v", got, want)
+	}
+	if got, want := testRunning.Calories(), 302.9145; got-want > 1e-3 || want-got > 1e-3 {
+		t.Errorf("Calories() above floor = %v, want unchanged %v", got, want)
+	}
+	SetMinCaloriesPerMinute(5)
+	noHeight := newWalking(500, 1, time.Hour)
+	noHeight.Height = 0
+	if got := noHeight.Calories(); !almostEqual(got, 300) {
+		t.Errorf("Calories() without height with floor = %v, want 300", got)
+	}
+
+	SetMinCaloriesPerMinute(-5)
+	if got := slow.Calories(); !almostEqual(got, base) {
+		t.Errorf("Calories() with negative floor = %v, want %v", got, base)
+	}
+}
+
+func TestRelativeEffort(t *testing.T) {
+	r := testRunning
+	r.AvgHeartRate = 130
+	if got := r.RelativeEffort(200, 40, true); got != 32 {
+		t.Errorf("RelativeEffort() = %d, want 32", got)
+	}
+	if got := testRunning.RelativeEffort(200, 40, true); got != 0 {
+		t.Errorf("RelativeEffort() without heart rate = %d, want 0", got)
+	}
+
+	// 10 * 0.55 * 0.64 * e^(1.92 * 0.55) + 20 * 0.85 * 0.64 * e^(1.92 * 0.85) ≈ 10.12 + 55.64
+	zones := testRunning
+	zones.HRZoneMinutes = map[int]float64{1: 10, 4: 20, 7: 5}
+	if got := zones.RelativeEffort(200, 40, true); got != 66 {
+		t.Errorf("RelativeEffort() by zones = %d, want 66", got)
+	}
+	if got := zones.RelativeEffort(200, 40, false); got != 72 {
+		t.Errorf("RelativeEffort() by zones for female = %d, want 72", got)
+	}
+}
+
+func TestPaceTargets(t *testing.T) {
+	got := PaceTargets([]float64{1, 5, 10, 12, 0}, 50*time.Minute, 10)
+	want := map[float64]time.Duration{1: 5 * time.Minute, 5: 25 * time.Minute, 10: 50 * time.Minute}
+	if len(got) != len(want) {
+		t.Fatalf("PaceTargets() = %v, want %v", got, want)
+	}
+	for distance, split := range want {
+		if got[distance] != split {
+			t.Errorf("PaceTargets()[%v] = %v, want %v", distance, got[distance], split)
+		}
+	}
+	if got := PaceTargets([]float64{5}, 0, 10); got != nil {
+		t.Errorf("PaceTargets() with zero target time = %v, want nil", got)
+	}
+}
+
+func TestCaloriesAtTemperature(t *testing.T) {
+	calories := testRunning.Calories()
+	tests := []struct {
+		temp, factor float64
+	}{
+		{20, 1},
+		{0, 1.2},
+		{35, 1.1125},
+	}
+	for _, tt := range tests {
+		if got := CaloriesAtTemperature(testRunning, tt.temp); !almostEqual(got, calories*tt.factor) {
+			t.Errorf("CaloriesAtTemperature(%v) = %v, want %v", tt.temp, got, calories*tt.factor)
+		}
+	}
+}
+
+func TestZone2Minutes(t *testing.T) {
+	r := testRunning
+	r.HRZoneMinutes = map[int]float64{1: 5, 2: 40, 3: 5}
+	if got := Zone2Minutes(r, 190); got != 40 {
+		t.Errorf("Zone2Minutes() from zones = %v, want 40", got)
+	}
+	r.HRZoneMinutes = map[int]float64{3: 30}
+	r.AvgHeartRate = 125 // по пульсу была бы вторая зона, но записанные зоны важнее
+	if got := Zone2Minutes(r, 190); got != 0 {
+		t.Errorf("Zone2Minutes() with zones but no zone 2 = %v, want 0", got)
+	}
+
+	r.HRZoneMinutes = nil
+	r.AvgHeartRate = 125 // 66% от 190
+	if got := Zone2Minutes(r, 190); got != 30 {
+		t.Errorf("Zone2Minutes() by heart rate = %v, want 30", got)
+	}
+	r.AvgHeartRate = 170
+	if got := Zone2Minutes(r, 190); got != 0 {
+		t.Errorf("Zone2Minutes() above zone 2 = %v, want 0", got)
+	}
+
+	easy := newRunning(8000, 1, time.Hour) // интенсивность 1/3
+	if got := Zone2Minutes(easy, 0); got != 60 {
+		t.Errorf("Zone2Minutes() by intensity = %v, want 60", got)
+	}
+	if got := Zone2Minutes(newRunning(14000, 1, time.Hour), 0); got != 0 {
+		t.Errorf("Zone2Minutes() for hard run by intensity = %v, want 0", got)
+	}
+}